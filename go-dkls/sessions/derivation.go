@@ -0,0 +1,73 @@
+// Provides a typed BIP32 derivation path for the derive and sign APIs.
+//
+// The Rust library takes derivation paths as strings and reports malformed ones
+// only as LIB_INVALID_DERIVATION_PATH_STR. DerivationPath is validated in Go
+// and holds the canonical string, so it can be passed wherever the bindings take
+// a derivation path or chain path byte slice. It is the same type as the
+// DerivationPath of go-schnorr.
+//
+// A threshold key can only derive non-hardened children: a hardened child needs
+// the private key, which no party holds. Since chain paths are validated in Go,
+// DklsSignSetupMsgNew rejects a path with hardened segments such as
+// "m/44'/60'/0'/0/0" with ErrInvalidDerivationPathStr. The Rust library took it
+// and failed the sign sessions with ErrSetupMessageValidation instead, and
+// DklsKeyshareDeriveChildPublicKey reported ErrDerivation for it. Every path the
+// Rust library derives is still accepted.
+//
+// Key functionalities include:
+// - Parsing and validating derivation path strings
+// - The derivation paths of Vultisig wallets per chain
+package session
+
+import (
+	"github.com/vultisig/go-wrappers/internal/derivation"
+)
+
+// DerivationPath is a validated derivation path of non-hardened indices in its
+// canonical form, e.g. "m/44/60/0/0/0". The root path is "m". Its methods report
+// malformed paths with ErrInvalidDerivationPathStr.
+type DerivationPath = derivation.Path
+
+// Chain identifies a chain with a Vultisig derivation path.
+type Chain string
+
+const (
+	ChainBitcoin   Chain = "BTC"
+	ChainLitecoin  Chain = "LTC"
+	ChainDogecoin  Chain = "DOGE"
+	ChainEthereum  Chain = "ETH"
+	ChainCosmos    Chain = "ATOM"
+	ChainTHORChain Chain = "THORChain"
+	ChainTron      Chain = "TRX"
+)
+
+// ParseDerivationPath parses and validates a derivation path string.
+//
+// Parameters:
+//   - derivationPathStr: string - the derivation path, e.g. "m/0/1/42".
+//
+// Returns:
+//   - DerivationPath: the derivation path in canonical form.
+//   - error: ErrInvalidDerivationPathStr if the path is malformed or has hardened segments.
+func ParseDerivationPath(derivationPathStr string) (DerivationPath, error) {
+	return derivation.Parse(derivationPathStr)
+}
+
+// VultisigDerivationPath returns the derivation path of an address of a chain
+// in Vultisig wallets.
+//
+// These are not the BIP44 and BIP84 paths of the chains: Vultisig keeps their
+// layout but derives every segment as a normal index, e.g. "m/44/60/0/0/0" for
+// the first Ethereum address where BIP44 has "m/44'/60'/0'/0/0". The addresses
+// match those of Vultisig vaults, not those of a single-key wallet.
+//
+// Parameters:
+//   - chain: Chain - the chain.
+//   - index: uint32 - the address index.
+//
+// Returns:
+//   - DerivationPath: the derivation path.
+//   - error: ErrInvalidDerivationPathStr if the chain is unknown or the index is hardened.
+func VultisigDerivationPath(chain Chain, index uint32) (DerivationPath, error) {
+	return derivation.Vultisig(derivation.Secp256k1, string(chain), index)
+}
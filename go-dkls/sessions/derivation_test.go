@@ -0,0 +1,144 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/vultisig/go-wrappers/go-dkls/errors"
+	session "github.com/vultisig/go-wrappers/go-dkls/sessions"
+	testHelper "github.com/vultisig/go-wrappers/go-dkls/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDerivationPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		path    string
+		want    string
+		indices []uint32
+		err     bool
+	}{
+		{name: "root", path: "m", want: "m", indices: []uint32{}},
+		{name: "non-hardened path", path: "m/44/60/0/0/0", want: "m/44/60/0/0/0", indices: []uint32{44, 60, 0, 0, 0}},
+		{name: "leading zeros are canonicalized", path: "m/007/1", want: "m/7/1", indices: []uint32{7, 1}},
+		{name: "leading plus is canonicalized", path: "m/+7/1", want: "m/7/1", indices: []uint32{7, 1}},
+		{name: "hardened segments", path: "m/44'/60'/0'/0/0", err: true},
+		{name: "hardened segment with h", path: "m/0h", err: true},
+		{name: "hardened segment with H", path: "m/0H", err: true},
+		{name: "missing root", path: "44/60", err: true},
+		{name: "empty segment", path: "m//0", err: true},
+		{name: "trailing slash", path: "m/0/", err: true},
+		{name: "not a number", path: "m/x", err: true},
+		{name: "negative index", path: "m/-1", err: true},
+		{name: "index out of range", path: "m/2147483648", err: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path, err := session.ParseDerivationPath(tc.path)
+			if tc.err {
+				assert.ErrorIs(t, err, errors.ErrInvalidDerivationPathStr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, path.String())
+
+			indices, err := path.Indices()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.indices, indices)
+		})
+	}
+}
+
+func TestVultisigDerivationPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		chain session.Chain
+		index uint32
+		want  string
+	}{
+		{chain: session.ChainBitcoin, index: 0, want: "m/84/0/0/0/0"},
+		{chain: session.ChainLitecoin, index: 0, want: "m/84/2/0/0/0"},
+		{chain: session.ChainDogecoin, index: 0, want: "m/44/3/0/0/0"},
+		{chain: session.ChainEthereum, index: 0, want: "m/44/60/0/0/0"},
+		{chain: session.ChainEthereum, index: 3, want: "m/44/60/0/0/3"},
+		{chain: session.ChainCosmos, index: 0, want: "m/44/118/0/0/0"},
+		{chain: session.ChainTHORChain, index: 1, want: "m/44/931/0/0/1"},
+		{chain: session.ChainTron, index: 0, want: "m/44/195/0/0/0"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.want, func(t *testing.T) {
+			t.Parallel()
+
+			path, err := session.VultisigDerivationPath(tc.chain, tc.index)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, path.String())
+		})
+	}
+
+	_, err := session.VultisigDerivationPath("XRP", 0)
+	assert.ErrorIs(t, err, errors.ErrInvalidDerivationPathStr)
+
+	// Solana keys are Ed25519 keys of go-schnorr.
+	_, err = session.VultisigDerivationPath("SOL", 0)
+	assert.ErrorIs(t, err, errors.ErrInvalidDerivationPathStr)
+
+	_, err = session.VultisigDerivationPath(session.ChainEthereum, 1<<31)
+	assert.ErrorIs(t, err, errors.ErrInvalidDerivationPathStr)
+}
+
+func TestDerivationPathWithKeyshare(t *testing.T) {
+	t.Parallel()
+
+	shares, err := testHelper.RunKeygen(2, 2)
+	assert.NoError(t, err)
+
+	path, err := session.VultisigDerivationPath(session.ChainEthereum, 0)
+	assert.NoError(t, err)
+
+	publicKey, err := session.DklsKeyshareDeriveChildPublicKey(shares[0], path)
+	assert.NoError(t, err)
+
+	want, err := session.DklsKeyshareDeriveChildPublicKey(shares[0], []byte("m/44/60/0/0/0"))
+	assert.NoError(t, err)
+	assert.Equal(t, want, publicKey)
+
+	child, err := path.Child(7)
+	assert.NoError(t, err)
+	assert.Equal(t, "m/44/60/0/0/0/7", child.String())
+
+	// Spellings the Rust library derives are still accepted.
+	publicKey, err = session.DklsKeyshareDeriveChildPublicKey(shares[0], []byte("m/+44/060/0/0/0"))
+	assert.NoError(t, err)
+	assert.Equal(t, want, publicKey)
+
+	// The hardened spelling of the path is rejected up front; the Rust library
+	// reported ErrDerivation for it.
+	_, err = session.DklsKeyshareDeriveChildPublicKey(shares[0], []byte("m/44'/60'/0'/0/0"))
+	assert.ErrorIs(t, err, errors.ErrInvalidDerivationPathStr)
+
+	keyID, err := session.DklsKeyshareKeyID(shares[0])
+	assert.NoError(t, err)
+
+	_, err = session.DklsSignSetupMsgNew(keyID, path, make([]byte, 32), testHelper.PrepareIDSlice(2))
+	assert.NoError(t, err)
+
+	_, err = session.DklsSignSetupMsgNew(keyID, []byte("m/+44/060/0/0/0"), make([]byte, 32), testHelper.PrepareIDSlice(2))
+	assert.NoError(t, err)
+
+	// The Rust library created a setup message for the hardened spelling, whose
+	// sign sessions failed with ErrSetupMessageValidation.
+	_, err = session.DklsSignSetupMsgNew(keyID, []byte("m/44'/60'/0'/0/0"), make([]byte, 32), testHelper.PrepareIDSlice(2))
+	assert.ErrorIs(t, err, errors.ErrInvalidDerivationPathStr)
+}
@@ -0,0 +1,652 @@
+// Provides typed, self-freeing wrappers around the raw library handles.
+//
+// Every function in this package trades in the bare Handle type, which makes it
+// possible to pass a keyshare where a session is expected and to forget to free
+// a session on an error path. The types below own exactly one native handle each,
+// expose the operations valid for that kind of handle as methods and release it
+// with the matching `*_free` call on Close.
+//
+// Key functionalities include:
+// - Distinct Keyshare, RefreshShare, Presign, KeygenSession, SignSession, QcSession, ExportReceiver and ImportSession types
+// - Idempotent Close() calling the right native free function
+// - A finalizer safety net that logs and frees leaked handles
+package session
+
+import (
+	"log"
+	"runtime"
+	"sync/atomic"
+)
+
+// owned holds a single native handle together with the function releasing it.
+//
+// The handle is reset to zero on release, which the native library always
+// reports as an invalid handle, so any use after Close fails cleanly.
+type owned struct {
+	hnd  atomic.Int32
+	kind string
+	free func(Handle) error
+}
+
+func (o *owned) init(kind string, hnd Handle, free func(Handle) error) {
+	o.kind = kind
+	o.free = free
+	o.hnd.Store(int32(hnd))
+}
+
+// Handle returns the raw handle, or zero if it has already been released.
+func (o *owned) Handle() Handle {
+	return Handle(o.hnd.Load())
+}
+
+// Close releases the native handle. It is safe to call Close more than once;
+// only the first call reaches the native library.
+func (o *owned) Close() error {
+	hnd := Handle(o.hnd.Swap(0))
+	if hnd == 0 {
+		return nil
+	}
+
+	return o.free(hnd)
+}
+
+// detach gives up ownership of the handle without releasing it, e.g. once the
+// native library has consumed it.
+func (o *owned) detach() {
+	o.hnd.Store(0)
+}
+
+func (o *owned) finalize() {
+	hnd := o.Handle()
+	if hnd == 0 {
+		return
+	}
+
+	log.Printf("go-dkls: leaked %s handle %d, releasing it from finalizer", o.kind, hnd)
+
+	if err := o.Close(); err != nil {
+		log.Printf("go-dkls: failed to release leaked %s handle %d: %v", o.kind, hnd, err)
+	}
+}
+
+// Keyshare owns a keyshare handle.
+type Keyshare struct {
+	owned
+}
+
+// NewKeyshare takes ownership of a raw keyshare handle.
+//
+// Parameters:
+//   - hnd: Handle - a keyshare handle, e.g. returned by DklsKeygenSessionFinish.
+//
+// Returns:
+//   - *Keyshare: the typed keyshare; the caller must Close it.
+func NewKeyshare(hnd Handle) *Keyshare {
+	k := &Keyshare{}
+	k.init("keyshare", hnd, DklsKeyshareFree)
+	runtime.SetFinalizer(k, (*Keyshare).finalize)
+
+	return k
+}
+
+// KeyshareFromBytes deserializes a keyshare.
+//
+// Parameters:
+//   - buf: []byte - a byte slice containing keyshare data.
+//
+// Returns:
+//   - *Keyshare: the deserialized keyshare.
+//   - error: an error if the Rust function call fails or if any other issue occurs.
+func KeyshareFromBytes(buf []byte) (*Keyshare, error) {
+	hnd, err := DklsKeyshareFromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeyshare(hnd), nil
+}
+
+// ToBytes serializes the keyshare.
+func (k *Keyshare) ToBytes() ([]byte, error) {
+	defer runtime.KeepAlive(k)
+
+	return DklsKeyshareToBytes(k.Handle())
+}
+
+// PublicKey returns the public key of the keyshare.
+func (k *Keyshare) PublicKey() ([]byte, error) {
+	defer runtime.KeepAlive(k)
+
+	return DklsKeysharePublicKey(k.Handle())
+}
+
+// KeyID returns the key ID of the keyshare.
+func (k *Keyshare) KeyID() ([]byte, error) {
+	defer runtime.KeepAlive(k)
+
+	return DklsKeyshareKeyID(k.Handle())
+}
+
+// ChainCode returns the root chain code of the keyshare.
+func (k *Keyshare) ChainCode() ([]byte, error) {
+	defer runtime.KeepAlive(k)
+
+	return DklsKeyshareChainCode(k.Handle())
+}
+
+// Info returns the threshold, the number of parties and the party index of the keyshare.
+func (k *Keyshare) Info() (KeyshareInfo, error) {
+	defer runtime.KeepAlive(k)
+
+	return DklsKeyshareInfo(k.Handle())
+}
+
+// DeriveChildPublicKey derives a child public key for the derivation path, e.g. a DerivationPath.
+func (k *Keyshare) DeriveChildPublicKey(derivationPathStr []byte) ([]byte, error) {
+	defer runtime.KeepAlive(k)
+
+	return DklsKeyshareDeriveChildPublicKey(k.Handle(), derivationPathStr)
+}
+
+// VerifySignature verifies a signature against the public key of the keyshare, or
+// of its child key at the derivation path; nil verifies against the root key.
+func (k *Keyshare) VerifySignature(derivationPathStr []byte, messageHash []byte, signature []byte) error {
+	defer runtime.KeepAlive(k)
+
+	return DklsVerifySignature(k.Handle(), derivationPathStr, messageHash, signature)
+}
+
+// ToRefreshBytes serializes the compact refresh form of the keyshare.
+func (k *Keyshare) ToRefreshBytes() ([]byte, error) {
+	defer runtime.KeepAlive(k)
+
+	return DklsKeyshareToRefreshBytes(k.Handle())
+}
+
+// QcSetupMsgNew generates a QC setup message for the key of this keyshare.
+func (k *Keyshare) QcSetupMsgNew(threshold int, ids []string, oldParties []int, newParties []int) ([]byte, error) {
+	defer runtime.KeepAlive(k)
+
+	return DklsQcSetupMsgNew(k.Handle(), threshold, ids, oldParties, newParties)
+}
+
+// Export encrypts the share of the private key for a key export receiver.
+//
+// Returns the encrypted message and the ID of the receiver it is meant for.
+func (k *Keyshare) Export(id string, setupMsg []byte) ([]byte, string, error) {
+	defer runtime.KeepAlive(k)
+
+	return DklsKeyExporter(k.Handle(), id, setupMsg)
+}
+
+// RefreshShare owns a refresh share handle, the compact backup form of a keyshare.
+//
+// A refresh share can not sign. It is turned back into a full keyshare of the
+// same key by a key recovery session run together with the co-signers. Keep the
+// bytes of Keyshare.ToRefreshBytes as the backup; the bundled library does not
+// serialize a deserialized refresh share again.
+type RefreshShare struct {
+	owned
+}
+
+// NewRefreshShare takes ownership of a raw refresh share handle.
+func NewRefreshShare(hnd Handle) *RefreshShare {
+	r := &RefreshShare{}
+	// The library has no dedicated refresh share free function,
+	// dkls_keyshare_free releases any object handle.
+	r.init("refresh share", hnd, DklsKeyshareFree)
+	runtime.SetFinalizer(r, (*RefreshShare).finalize)
+
+	return r
+}
+
+// RefreshShareFromBytes deserializes a refresh share, e.g. the output of Keyshare.ToRefreshBytes.
+//
+// Parameters:
+//   - buf: []byte - a byte slice containing refresh share data.
+//
+// Returns:
+//   - *RefreshShare: the deserialized refresh share.
+//   - error: an error if the Rust function call fails or if any other issue occurs.
+func RefreshShareFromBytes(buf []byte) (*RefreshShare, error) {
+	hnd, err := DklsRefreshShareFromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRefreshShare(hnd), nil
+}
+
+// Presign owns a pre-signature handle.
+type Presign struct {
+	owned
+}
+
+// NewPresign takes ownership of a raw pre-signature handle.
+func NewPresign(hnd Handle) *Presign {
+	p := &Presign{}
+	// The library has no dedicated pre-signature free function,
+	// dkls_keyshare_free releases any object handle.
+	p.init("presign", hnd, DklsKeyshareFree)
+	runtime.SetFinalizer(p, (*Presign).finalize)
+
+	return p
+}
+
+// PresignFromBytes deserializes a pre-signature.
+//
+// Parameters:
+//   - buf: []byte - a byte slice containing the serialized pre-signature.
+//
+// Returns:
+//   - *Presign: the deserialized pre-signature.
+//   - error: an error if the Rust function call fails or if any other issue occurs.
+func PresignFromBytes(buf []byte) (*Presign, error) {
+	hnd, err := DklsPresignFromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPresign(hnd), nil
+}
+
+// ToBytes serializes the pre-signature.
+func (p *Presign) ToBytes() ([]byte, error) {
+	defer runtime.KeepAlive(p)
+
+	return DklsPresignToBytes(p.Handle())
+}
+
+// SessionID returns the session ID the pre-signature was generated in.
+func (p *Presign) SessionID() ([]byte, error) {
+	defer runtime.KeepAlive(p)
+
+	return DklsPresignSessionID(p.Handle())
+}
+
+// KeygenSession owns a key generation, key refresh or key migration session handle.
+type KeygenSession struct {
+	owned
+}
+
+func newKeygenSession(kind string, hnd Handle) *KeygenSession {
+	s := &KeygenSession{}
+	s.init(kind, hnd, DklsKeygenSessionFree)
+	runtime.SetFinalizer(s, (*KeygenSession).finalize)
+
+	return s
+}
+
+// NewKeygenSession creates a key generation session from a setup message.
+//
+// Parameters:
+//   - setup: []byte - the keygen setup message.
+//   - id: []byte - the participant's identifier.
+//
+// Returns:
+//   - *KeygenSession: the session; the caller must Close it.
+//   - error: an error if the Rust function call fails or if any other issue occurs.
+func NewKeygenSession(setup []byte, id []byte) (*KeygenSession, error) {
+	hnd, err := DklsKeygenSessionFromSetup(setup, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return newKeygenSession("keygen session", hnd), nil
+}
+
+// NewKeyRefreshSession creates a key refresh session from a setup message.
+//
+// Parameters:
+//   - setup: []byte - the keygen setup message carrying the key ID of the refreshed key.
+//   - id: []byte - the participant's identifier.
+//   - oldKeyshare: *Keyshare - the keyshare to be refreshed.
+//
+// Returns:
+//   - *KeygenSession: the session; the caller must Close it.
+//   - error: an error if the Rust function call fails or if any other issue occurs.
+func NewKeyRefreshSession(setup []byte, id []byte, oldKeyshare *Keyshare) (*KeygenSession, error) {
+	defer runtime.KeepAlive(oldKeyshare)
+
+	hnd, err := DklsKeyRefreshSessionFromSetup(setup, id, oldKeyshare.Handle())
+	if err != nil {
+		return nil, err
+	}
+
+	return newKeygenSession("key refresh session", hnd), nil
+}
+
+// NewKeyRecoverySession creates a key refresh session for a party that only
+// holds the refresh share of its keyshare. The other parties of the setup run
+// ordinary key refresh sessions with their full keyshares; on completion every
+// party, including the recovering one, holds a fresh full keyshare of the same
+// public key.
+//
+// Parameters:
+//   - setup: []byte - the keygen setup message carrying the key ID of the recovered key.
+//   - id: []byte - the participant's identifier.
+//   - refreshShare: *RefreshShare - the refresh share of the recovering party.
+//
+// Returns:
+//   - *KeygenSession: the session; the caller must Close it.
+//   - error: an error if the Rust function call fails or if any other issue occurs.
+func NewKeyRecoverySession(setup []byte, id []byte, refreshShare *RefreshShare) (*KeygenSession, error) {
+	defer runtime.KeepAlive(refreshShare)
+
+	hnd, err := DklsKeyRefreshSessionFromSetup(setup, id, refreshShare.Handle())
+	if err != nil {
+		return nil, err
+	}
+
+	return newKeygenSession("key recovery session", hnd), nil
+}
+
+// NewKeyMigrateSession creates a key migration session from a setup message.
+//
+// See DklsKeyMigrateSessionFromSetup for the meaning of the parameters.
+func NewKeyMigrateSession(setup []byte, id []byte, publicKey []byte, rootChainCode []byte, secretCoefficient []byte) (*KeygenSession, error) {
+	hnd, err := DklsKeyMigrateSessionFromSetup(setup, id, publicKey, rootChainCode, secretCoefficient)
+	if err != nil {
+		return nil, err
+	}
+
+	return newKeygenSession("key migration session", hnd), nil
+}
+
+// OutputMessage returns the next output message, or nil if there is none.
+func (s *KeygenSession) OutputMessage() ([]byte, error) {
+	defer runtime.KeepAlive(s)
+
+	return DklsKeygenSessionOutputMessage(s.Handle())
+}
+
+// MessageReceiver returns the receiver of a message with the given index,
+// or an empty string if there are no more receivers.
+func (s *KeygenSession) MessageReceiver(message []byte, index int) (string, error) {
+	defer runtime.KeepAlive(s)
+
+	return DklsKeygenSessionMessageReceiver(s.Handle(), message, index)
+}
+
+// InputMessage processes an input message and reports whether the session has finished.
+func (s *KeygenSession) InputMessage(message []byte) (bool, error) {
+	defer runtime.KeepAlive(s)
+
+	return DklsKeygenSessionInputMessage(s.Handle(), message)
+}
+
+// Finish finalizes the session and returns the generated keyshare.
+// The session itself still has to be closed.
+func (s *KeygenSession) Finish() (*Keyshare, error) {
+	defer runtime.KeepAlive(s)
+
+	hnd, err := DklsKeygenSessionFinish(s.Handle())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeyshare(hnd), nil
+}
+
+// ImportSession owns a key import session handle, either of the initiator
+// holding the private key or of an importer.
+//
+// The session is driven with the same calls as a key generation session.
+type ImportSession struct {
+	KeygenSession
+}
+
+func newImportSession(hnd Handle) *ImportSession {
+	s := &ImportSession{}
+	s.init("key import session", hnd, DklsKeygenSessionFree)
+	runtime.SetFinalizer(s, (*ImportSession).finalize)
+
+	return s
+}
+
+// NewKeyImportInitiator creates the key import session of the party holding the private key.
+//
+// See DklsKeyImportInitiatorNew for the meaning of the parameters.
+//
+// Returns:
+//   - *ImportSession: the session; the caller must Close it.
+//   - []byte: the setup message for the importers.
+//   - error: an error if the Rust function call fails or if any other issue occurs.
+func NewKeyImportInitiator(privateKey []byte, rootChain []byte, threshold uint8, ids []string) (*ImportSession, []byte, error) {
+	hnd, setup, err := DklsKeyImportInitiatorNew(privateKey, rootChain, threshold, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newImportSession(hnd), setup, nil
+}
+
+// NewKeyImporter creates the key import session of a receiving party.
+//
+// Parameters:
+//   - setupMsg: []byte - the setup message generated by the initiator.
+//   - id: string - human readable party identifier.
+//
+// Returns:
+//   - *ImportSession: the session; the caller must Close it.
+//   - error: an error if the Rust function call fails or if any other issue occurs.
+func NewKeyImporter(setupMsg []byte, id string) (*ImportSession, error) {
+	hnd, err := DklsKeyImporter(setupMsg, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return newImportSession(hnd), nil
+}
+
+// SignSession owns a sign, pre-sign or finish session handle.
+type SignSession struct {
+	owned
+}
+
+func newSignSession(hnd Handle) *SignSession {
+	s := &SignSession{}
+	s.init("sign session", hnd, DklsSignSessionFree)
+	runtime.SetFinalizer(s, (*SignSession).finalize)
+
+	return s
+}
+
+// NewSignSession creates a full sign or pre-sign session from a setup message.
+//
+// Parameters:
+//   - setup: []byte - the sign setup message; a setup without message hash creates a pre-sign session.
+//   - id: []byte - the identifier of the signer.
+//   - share: *Keyshare - the signer's keyshare.
+//
+// Returns:
+//   - *SignSession: the session; the caller must Close it.
+//   - error: an error if the Rust function call fails or if any other issue occurs.
+func NewSignSession(setup []byte, id []byte, share *Keyshare) (*SignSession, error) {
+	defer runtime.KeepAlive(share)
+
+	hnd, err := DklsSignSessionFromSetup(setup, id, share.Handle())
+	if err != nil {
+		return nil, err
+	}
+
+	return newSignSession(hnd), nil
+}
+
+// NewFinishSession creates a session finishing a signature from a pre-signature.
+// The session consumes the pre-signature: on success presign is left empty and
+// closing it is a no-op.
+//
+// Parameters:
+//   - setup: []byte - the finish setup message generated by DklsFinishSetupMsgNew.
+//   - id: []byte - the identifier of the signer.
+//   - presign: *Presign - the signer's pre-signature.
+//
+// Returns:
+//   - *SignSession: the session; the caller must Close it.
+//   - error: an error if the Rust function call fails or if any other issue occurs.
+func NewFinishSession(setup []byte, id []byte, presign *Presign) (*SignSession, error) {
+	defer runtime.KeepAlive(presign)
+
+	hnd, err := DklsSignSessionFromSetup(setup, id, presign.Handle())
+	if err != nil {
+		return nil, err
+	}
+
+	presign.detach()
+
+	return newSignSession(hnd), nil
+}
+
+// OutputMessage returns the next output message, or an empty slice if there is none.
+func (s *SignSession) OutputMessage() ([]byte, error) {
+	defer runtime.KeepAlive(s)
+
+	return DklsSignSessionOutputMessage(s.Handle())
+}
+
+// MessageReceiver returns the receiver of a message with the given index,
+// or an empty string if there are no more receivers.
+func (s *SignSession) MessageReceiver(message []byte, index int) (string, error) {
+	defer runtime.KeepAlive(s)
+
+	receiver, err := DklsSignSessionMessageReceiver(s.Handle(), message, index)
+	if err != nil {
+		return "", err
+	}
+
+	return string(receiver), nil
+}
+
+// InputMessage processes an input message and reports whether the session has finished.
+func (s *SignSession) InputMessage(message []byte) (bool, error) {
+	defer runtime.KeepAlive(s)
+
+	return DklsSignSessionInputMessage(s.Handle(), message)
+}
+
+// Finish finalizes the session. For sign and finish sessions the result is an
+// ECDSA signature [ R || S || rec-id ], for pre-sign sessions the serialized
+// pre-signature. The session itself still has to be closed.
+func (s *SignSession) Finish() ([]byte, error) {
+	defer runtime.KeepAlive(s)
+
+	return DklsSignSessionFinish(s.Handle())
+}
+
+// QcSession owns a quorum change session handle.
+type QcSession struct {
+	owned
+}
+
+// NewQcSession creates a QC session from a setup message.
+//
+// Parameters:
+//   - setupMsg: []byte - the QC setup message.
+//   - id: string - human readable party identifier.
+//   - keyshare: *Keyshare - the party's keyshare, or nil for a party joining the quorum.
+//
+// Returns:
+//   - *QcSession: the session; the caller must Close it.
+//   - error: an error if the Rust function call fails or if any other issue occurs.
+func NewQcSession(setupMsg []byte, id string, keyshare *Keyshare) (*QcSession, error) {
+	var share Handle
+	if keyshare != nil {
+		share = keyshare.Handle()
+		defer runtime.KeepAlive(keyshare)
+	}
+
+	hnd, err := DklsQcSessionFromSetup(setupMsg, id, share)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &QcSession{}
+	s.init("qc session", hnd, DklsQcSessionFree)
+	runtime.SetFinalizer(s, (*QcSession).finalize)
+
+	return s, nil
+}
+
+// OutputMessage returns the next output message, or nil if there is none.
+func (s *QcSession) OutputMessage() ([]byte, error) {
+	defer runtime.KeepAlive(s)
+
+	return DklsQcSessionOutputMessage(s.Handle())
+}
+
+// MessageReceiver returns the receiver of a message with the given index,
+// or an empty string if there are no more receivers.
+func (s *QcSession) MessageReceiver(message []byte, index int) (string, error) {
+	defer runtime.KeepAlive(s)
+
+	return DklsQcSessionMessageReceiver(s.Handle(), message, index)
+}
+
+// InputMessage processes an input message and reports whether the session has finished.
+func (s *QcSession) InputMessage(message []byte) (bool, error) {
+	defer runtime.KeepAlive(s)
+
+	return DklsQcSessionInputMessage(s.Handle(), message)
+}
+
+// Finish finalizes the session and returns the new keyshare.
+// The session itself still has to be closed.
+func (s *QcSession) Finish() (*Keyshare, error) {
+	defer runtime.KeepAlive(s)
+
+	hnd, err := DklsQcSessionFinish(s.Handle())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeyshare(hnd), nil
+}
+
+// ExportReceiver owns a key export receiver session handle.
+type ExportReceiver struct {
+	owned
+}
+
+// NewKeyExportReceiver creates a key export receiver session.
+//
+// Parameters:
+//   - share: *Keyshare - the receiver's keyshare.
+//   - ids: []string - human readable party identifiers.
+//
+// Returns:
+//   - *ExportReceiver: the session; the caller must Close it.
+//   - []byte: the setup message for the key exporters.
+//   - error: an error if the Rust function call fails or if any other issue occurs.
+func NewKeyExportReceiver(share *Keyshare, ids []string) (*ExportReceiver, []byte, error) {
+	defer runtime.KeepAlive(share)
+
+	hnd, setup, err := DklsKeyExportReceiverNew(share.Handle(), ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := &ExportReceiver{}
+	// The library has no dedicated export receiver free function,
+	// dkls_keyshare_free releases any object handle.
+	r.init("key export receiver", hnd, DklsKeyshareFree)
+	runtime.SetFinalizer(r, (*ExportReceiver).finalize)
+
+	return r, setup, nil
+}
+
+// InputMessage processes a message from a key exporter and reports whether
+// all expected messages have been received.
+func (r *ExportReceiver) InputMessage(message []byte) (bool, error) {
+	defer runtime.KeepAlive(r)
+
+	return DklsKeyExportReceiverInputMessage(r.Handle(), message)
+}
+
+// Finish finalizes the session and returns the exported private key.
+// The session itself still has to be closed.
+func (r *ExportReceiver) Finish() ([]byte, error) {
+	defer runtime.KeepAlive(r)
+
+	return DklsKeyExportReceiverFinish(r.Handle())
+}
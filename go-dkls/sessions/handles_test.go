@@ -0,0 +1,238 @@
+package session_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/vultisig/go-wrappers/go-dkls/errors"
+	session "github.com/vultisig/go-wrappers/go-dkls/sessions"
+	testHelper "github.com/vultisig/go-wrappers/go-dkls/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func runTypedKeygen(t *testing.T, threshold int, n int) []*session.Keyshare {
+	setup, err := session.DklsKeygenSetupMsgNew(threshold, nil, testHelper.PrepareIDSlice(n))
+	assert.NoError(t, err)
+
+	sessions := make(map[string]*session.KeygenSession, n)
+	for i := 1; i <= n; i++ {
+		id := fmt.Sprintf("p%d", i)
+
+		s, err := session.NewKeygenSession(setup, []byte(id))
+		assert.NoError(t, err)
+
+		defer s.Close()
+
+		sessions[id] = s
+	}
+
+	shares := make([]*session.Keyshare, n)
+	finished := make(map[string]bool, n)
+
+	for len(finished) != n {
+		msgq := make(map[string][][]byte)
+
+		for _, s := range sessions {
+			for {
+				msg, err := s.OutputMessage()
+				assert.NoError(t, err)
+
+				if msg == nil {
+					break
+				}
+
+				for idx := 0; idx < n; idx++ {
+					receiver, err := s.MessageReceiver(msg, idx)
+					assert.NoError(t, err)
+
+					if receiver == "" {
+						break
+					}
+
+					msgq[receiver] = append(msgq[receiver], msg)
+				}
+			}
+		}
+
+		for i := 1; i <= n; i++ {
+			id := fmt.Sprintf("p%d", i)
+
+			for _, msg := range msgq[id] {
+				done, err := sessions[id].InputMessage(msg)
+				assert.NoError(t, err)
+
+				if done && !finished[id] {
+					finished[id] = true
+
+					shares[i-1], err = sessions[id].Finish()
+					assert.NoError(t, err)
+				}
+			}
+		}
+	}
+
+	return shares
+}
+
+func TestTypedHandles(t *testing.T) {
+	t.Parallel()
+
+	shares := runTypedKeygen(t, 2, 2)
+
+	publicKey, err := shares[0].PublicKey()
+	assert.NoError(t, err)
+	assert.Len(t, publicKey, 33)
+
+	buf, err := shares[0].ToBytes()
+	assert.NoError(t, err)
+
+	restored, err := session.KeyshareFromBytes(buf)
+	assert.NoError(t, err)
+
+	restoredPublicKey, err := restored.PublicKey()
+	assert.NoError(t, err)
+	assert.Equal(t, publicKey, restoredPublicKey)
+
+	assert.NoError(t, restored.Close())
+	assert.NoError(t, restored.Close())
+	assert.Zero(t, restored.Handle())
+
+	_, err = restored.PublicKey()
+	assert.Error(t, err)
+
+	for _, share := range shares {
+		assert.NoError(t, share.Close())
+	}
+}
+
+func TestTypedRefreshShare(t *testing.T) {
+	t.Parallel()
+
+	shares := runTypedKeygen(t, 2, 2)
+
+	buf, err := shares[0].ToRefreshBytes()
+	assert.NoError(t, err)
+
+	refreshShare, err := session.RefreshShareFromBytes(buf)
+	assert.NoError(t, err)
+
+	_, err = session.DklsRefreshShareToBytes(refreshShare.Handle())
+	assert.ErrorIs(t, err, errors.ErrInvalidHandleType)
+
+	assert.NoError(t, refreshShare.Close())
+	assert.NoError(t, refreshShare.Close())
+	assert.Zero(t, refreshShare.Handle())
+
+	_, err = session.RefreshShareFromBytes([]byte{1, 2, 3})
+	assert.Error(t, err)
+
+	for _, share := range shares {
+		assert.NoError(t, share.Close())
+	}
+}
+
+func TestTypedSignSession(t *testing.T) {
+	t.Parallel()
+
+	shares := runTypedKeygen(t, 2, 3)
+
+	defer func() {
+		for _, share := range shares {
+			share.Close()
+		}
+	}()
+
+	keyID, err := shares[0].KeyID()
+	assert.NoError(t, err)
+
+	msg := make([]byte, 32)
+	for i := range msg {
+		msg[i] = 7
+	}
+
+	setup, err := session.DklsSignSetupMsgNew(keyID, nil, msg, testHelper.PrepareIDSlice(2))
+	assert.NoError(t, err)
+
+	signers := make([]*session.SignSession, 2)
+	for i := range signers {
+		signers[i], err = session.NewSignSession(setup, []byte(fmt.Sprintf("p%d", i+1)), shares[i])
+		assert.NoError(t, err)
+	}
+
+	signatures := make([][]byte, 2)
+	for signatures[0] == nil || signatures[1] == nil {
+		msgq := make(map[string][][]byte)
+
+		for _, s := range signers {
+			for {
+				buf, err := s.OutputMessage()
+				assert.NoError(t, err)
+
+				if len(buf) == 0 {
+					break
+				}
+
+				for idx := 0; idx < 2; idx++ {
+					receiver, err := s.MessageReceiver(buf, idx)
+					assert.NoError(t, err)
+
+					if receiver == "" {
+						break
+					}
+
+					msgq[receiver] = append(msgq[receiver], buf)
+				}
+			}
+		}
+
+		for i, s := range signers {
+			for _, buf := range msgq[fmt.Sprintf("p%d", i+1)] {
+				done, err := s.InputMessage(buf)
+				assert.NoError(t, err)
+
+				if done {
+					signatures[i], err = s.Finish()
+					assert.NoError(t, err)
+				}
+			}
+		}
+	}
+
+	assert.Equal(t, signatures[0], signatures[1])
+	assert.Len(t, signatures[0], 65)
+
+	for _, s := range signers {
+		assert.NoError(t, s.Close())
+		assert.NoError(t, s.Close())
+	}
+}
+
+func TestTypedExportReceiver(t *testing.T) {
+	t.Parallel()
+
+	shares := runTypedKeygen(t, 2, 3)
+
+	receiver, setup, err := session.NewKeyExportReceiver(shares[0], []string{"p1", "p2", "p3"})
+	assert.NoError(t, err)
+
+	for i, id := range []string{"p2", "p3"} {
+		msg, to, err := shares[i+1].Export(id, setup)
+		assert.NoError(t, err)
+		assert.Equal(t, "p1", to)
+
+		finished, err := receiver.InputMessage(msg)
+		assert.NoError(t, err)
+		assert.Equal(t, id == "p3", finished)
+	}
+
+	secret, err := receiver.Finish()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	assert.NoError(t, receiver.Close())
+
+	for _, share := range shares {
+		assert.NoError(t, share.Close())
+	}
+}
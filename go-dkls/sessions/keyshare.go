@@ -10,6 +10,7 @@
 // - Obtaining the key ID of a key share
 // - Deriving a hierarchical family of keys from a root private key
 // - Freeing resources associated with key shares
+// - Reading the threshold, party count and party index of a key share
 package session
 
 /*
@@ -18,6 +19,8 @@ package session
 */
 import "C"
 import (
+	"encoding/binary"
+	"fmt"
 	"runtime"
 	"unsafe"
 
@@ -137,12 +140,17 @@ func DklsKeyshareKeyID(share Handle) ([]byte, error) {
 //
 // Parameters:
 //   - share: Handle - a handle representing the root keyshare from which the child key will be derived.
-//   - derivationPathStr: []byte - a byte slice representing the derivation path for the child key.
+//   - derivationPathStr: []byte - a byte slice representing the derivation path for the child key,
+//     e.g. a DerivationPath.
 //
 // Returns:
 //   - []byte: a byte slice containing the derived child public key.
 //   - error: an error if the Rust function call fails or if any other issue occurs.
 func DklsKeyshareDeriveChildPublicKey(share Handle, derivationPathStr []byte) ([]byte, error) {
+	if _, err := DerivationPath(derivationPathStr).Indices(); err != nil {
+		return nil, err
+	}
+
 	pinner := new(runtime.Pinner)
 	defer pinner.Unpin()
 
@@ -193,6 +201,17 @@ func DklsKeyshareToRefreshBytes(share Handle) ([]byte, error) {
 	return buf, nil
 }
 
+// DklsRefreshShareFromBytes deserializes a refresh share produced by DklsKeyshareToRefreshBytes.
+//
+// The returned handle can be passed as the old keyshare to DklsKeyRefreshSessionFromSetup,
+// which regenerates a full keyshare of the same key. It is released with DklsKeyshareFree.
+//
+// Parameters:
+//   - buf: []byte - a byte slice containing refresh share data.
+//
+// Returns:
+//   - Handle: a handle representing the refresh share.
+//   - error: an error if the Rust function call fails or if any other issue occurs during deserialization.
 func DklsRefreshShareFromBytes(buf []byte) (Handle, error) {
 	pinner := new(runtime.Pinner)
 	defer pinner.Unpin()
@@ -212,6 +231,18 @@ func DklsRefreshShareFromBytes(buf []byte) (Handle, error) {
 	return Handle(cHnd._0), nil
 }
 
+// DklsRefreshShareToBytes serializes a refresh share handle.
+//
+// Note: the bundled library rejects both keyshare handles and the handles of
+// DklsRefreshShareFromBytes with ErrInvalidHandleType; back up the output of
+// DklsKeyshareToRefreshBytes instead.
+//
+// Parameters:
+//   - share: Handle - a handle representing the refresh share to be serialized.
+//
+// Returns:
+//   - []byte: a byte slice containing the serialized refresh share.
+//   - error: an error if the Rust function call fails or if any other issue occurs during serialization.
 func DklsRefreshShareToBytes(share Handle) ([]byte, error) {
 	cShare := cHandle(share)
 
@@ -277,3 +308,114 @@ func DklsKeyshareChainCode(share Handle) ([]byte, error) {
 
 	return retBuf, nil
 }
+
+// KeyshareInfo describes the quorum a keyshare belongs to.
+//
+// Party names are not part of a keyshare: the setup message maps party names
+// to indices. The index of a party is its position in the ids list of the
+// keygen or refresh setup message which produced the share, and for a QC its
+// position among the new parties of the setup message.
+type KeyshareInfo struct {
+	// Threshold is the number of parties needed to sign.
+	Threshold int
+	// Parties is the total number of parties.
+	Parties int
+	// PartyIndex is the zero-based index of the owner of the keyshare, as used
+	// by DklsQcSetupMsgNew.
+	PartyIndex int
+}
+
+const (
+	// dklsKeyshareVersion is the serialization format version DklsKeyshareInfo reads.
+	dklsKeyshareVersion = 1
+	// dklsKeyshareHeaderSize is the size of the serialized keyshare header:
+	// a 4-byte big-endian format version, 4 reserved zero bytes, the number of
+	// parties, the threshold and the party index.
+	dklsKeyshareHeaderSize = 11
+)
+
+// DklsKeyshareInfo returns the threshold, the number of parties and the party
+// index of a keyshare.
+//
+// The native library has no accessor for these values, so they are read from
+// the versioned header of the serialized keyshare; the rest of the
+// serialization is not inspected. A header of any format version other than 1
+// is rejected with errors.ErrSerialization.
+//
+// Parameters:
+//   - share: Handle - a handle representing the keyshare.
+//
+// Returns:
+//   - KeyshareInfo: the quorum of the keyshare.
+//   - error: an error if the Rust function call fails or the serialization is not recognized.
+func DklsKeyshareInfo(share Handle) (KeyshareInfo, error) {
+	buf, err := DklsKeyshareToBytes(share)
+	if err != nil {
+		return KeyshareInfo{}, err
+	}
+
+	defer clear(buf)
+
+	if len(buf) < dklsKeyshareHeaderSize ||
+		binary.BigEndian.Uint32(buf[0:4]) != dklsKeyshareVersion ||
+		binary.BigEndian.Uint32(buf[4:8]) != 0 {
+		return KeyshareInfo{}, fmt.Errorf("%w: unknown keyshare format", errors.ErrSerialization)
+	}
+
+	info := KeyshareInfo{
+		Parties:    int(buf[8]),
+		Threshold:  int(buf[9]),
+		PartyIndex: int(buf[10]),
+	}
+
+	if info.Threshold == 0 || info.Threshold > info.Parties || info.PartyIndex >= info.Parties {
+		return KeyshareInfo{}, fmt.Errorf("%w: invalid keyshare header", errors.ErrSerialization)
+	}
+
+	return info, nil
+}
+
+// DklsRefreshShareInfo returns the threshold, the number of parties and the
+// party index of a refresh share of DklsKeyshareToRefreshBytes.
+//
+// The native library has no accessor for these values, so they are read from
+// the header of the refresh share: the party index, the number of parties, the
+// rank of every party and the threshold. The rest of the refresh share is not
+// inspected. A header with ranks other than zero is rejected with
+// errors.ErrSerialization.
+//
+// Parameters:
+//   - buf: []byte - a byte slice containing refresh share data.
+//
+// Returns:
+//   - KeyshareInfo: the quorum of the refresh share.
+//   - error: an error if the refresh share is not recognized.
+func DklsRefreshShareInfo(buf []byte) (KeyshareInfo, error) {
+	if len(buf) < 2 {
+		return KeyshareInfo{}, fmt.Errorf("%w: unknown refresh share format", errors.ErrSerialization)
+	}
+
+	parties := int(buf[1])
+
+	if len(buf) < 3+parties {
+		return KeyshareInfo{}, fmt.Errorf("%w: unknown refresh share format", errors.ErrSerialization)
+	}
+
+	for _, rank := range buf[2 : 2+parties] {
+		if rank != 0 {
+			return KeyshareInfo{}, fmt.Errorf("%w: unknown refresh share format", errors.ErrSerialization)
+		}
+	}
+
+	info := KeyshareInfo{
+		Threshold:  int(buf[2+parties]),
+		Parties:    parties,
+		PartyIndex: int(buf[0]),
+	}
+
+	if info.Threshold == 0 || info.Threshold > info.Parties || info.PartyIndex >= info.Parties {
+		return KeyshareInfo{}, fmt.Errorf("%w: invalid refresh share header", errors.ErrSerialization)
+	}
+
+	return info, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/vultisig/go-wrappers/go-dkls/errors"
 	session "github.com/vultisig/go-wrappers/go-dkls/sessions"
 	testHelper "github.com/vultisig/go-wrappers/go-dkls/test"
 
@@ -102,6 +103,26 @@ func TestDklsKeyshare(t *testing.T) {
 			assert.NoError(t, err)
 			assert.NotEmpty(t, keyShare)
 
+			// the serialization format read by DklsKeyshareInfo
+			assert.Equal(t, []byte{0, 0, 0, 1, 0, 0, 0, 0, byte(tc.input.N), byte(tc.input.T)}, keyShareBytes[:10])
+
+			// key share info
+			indices := make(map[int]bool)
+
+			for _, share := range shares {
+				info, err := session.DklsKeyshareInfo(share)
+
+				assert.NoError(t, err)
+				assert.Equal(t, tc.input.T, info.Threshold)
+				assert.Equal(t, tc.input.N, info.Parties)
+
+				indices[info.PartyIndex] = true
+			}
+
+			for idx := 0; idx < tc.input.N; idx++ {
+				assert.True(t, indices[idx])
+			}
+
 			// key share public key
 			keySharePublicKey, err := session.DklsKeysharePublicKey(shares[0])
 
@@ -120,6 +141,19 @@ func TestDklsKeyshare(t *testing.T) {
 			assert.NoError(t, err)
 			assert.NotEmpty(t, keyShareToRefreshBytes)
 
+			// refresh share info
+			for _, share := range shares {
+				refreshShare, err := session.DklsKeyshareToRefreshBytes(share)
+				assert.NoError(t, err)
+
+				refreshInfo, err := session.DklsRefreshShareInfo(refreshShare)
+				assert.NoError(t, err)
+
+				info, err := session.DklsKeyshareInfo(share)
+				assert.NoError(t, err)
+				assert.Equal(t, info, refreshInfo)
+			}
+
 			// derive child1 public key
 			derivedChildPublicKey, err := session.DklsKeyshareDeriveChildPublicKey(shares[0], []byte("m"))
 
@@ -164,3 +198,57 @@ func TestDklsKeyshare(t *testing.T) {
 		})
 	}
 }
+
+func TestDklsRefreshShareInfoUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	shares, err := testHelper.RunKeygen(2, 3)
+	assert.NoError(t, err)
+
+	refreshShare, err := session.DklsKeyshareToRefreshBytes(shares[0])
+	assert.NoError(t, err)
+
+	// Only the header is read, so a longer serialization of a later native
+	// library still reports its quorum.
+	info, err := session.DklsRefreshShareInfo(append(append([]byte(nil), refreshShare...), 0, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, session.KeyshareInfo{Threshold: 2, Parties: 3, PartyIndex: info.PartyIndex}, info)
+
+	testCases := []struct {
+		name   string
+		tamper func(buf []byte) []byte
+	}{
+		{
+			name:   "truncated header",
+			tamper: func(buf []byte) []byte { return buf[:5] },
+		},
+		{
+			name: "non-zero rank",
+			tamper: func(buf []byte) []byte {
+				buf[3] = 1
+
+				return buf
+			},
+		},
+		{
+			name: "party index out of range",
+			tamper: func(buf []byte) []byte {
+				buf[0] = 3
+
+				return buf
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := tc.tamper(append([]byte(nil), refreshShare...))
+
+			_, err := session.DklsRefreshShareInfo(buf)
+			assert.ErrorIs(t, err, errors.ErrSerialization)
+		})
+	}
+}
@@ -214,3 +214,21 @@ func DklsQcSessionFinish(session Handle) (Handle, error) {
 
 	return Handle(cKeyshareHandle._0), nil
 }
+
+// DklsQcSessionFree removes the data associated with a QC session.
+//
+// Parameters:
+//   - session: Handle - A handle representing the QC session to be removed.
+//
+// Returns:
+//   - error: An error is returned if the Rust function call fails or if any issue occurs during session finalization.
+func DklsQcSessionFree(session Handle) error {
+	cSession := cHandle(session)
+
+	res := C.dkls_qc_session_free(&cSession)
+	if res != 0 {
+		return errors.MapLibError(int(res))
+	}
+
+	return nil
+}
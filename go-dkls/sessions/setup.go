@@ -5,6 +5,9 @@
 // - Decoding the key ID from a setup message.
 // - Decoding the message from a setup message.
 // - Retrieving the names of parties by their respective indices.
+// - Decoding the session ID from a setup message.
+// - Decoding the derivation path from a sign setup message.
+// - Decoding all fields of a setup message at once.
 package session
 
 /*
@@ -13,10 +16,12 @@ package session
 */
 import "C"
 import (
+	"fmt"
 	"runtime"
 	"unsafe"
 
 	"github.com/vultisig/go-wrappers/go-dkls/errors"
+	"github.com/vultisig/go-wrappers/internal/setupmsg"
 )
 
 // DklsDecodeKeyID decodes a key ID from a setup message.
@@ -49,6 +54,36 @@ func DklsDecodeKeyID(setup []byte) ([]byte, error) {
 	return KeyID, nil
 }
 
+// DklsDecodeSessionID decodes a session ID from a setup message.
+//
+// Parameters:
+//   - setup: []byte - a byte slice containing the setup message from which the session ID will be decoded.
+//
+// Returns:
+//   - []byte: the decoded session ID as a byte slice.
+//   - error: an error if the Rust function call fails or if any other issue occurs.
+func DklsDecodeSessionID(setup []byte) ([]byte, error) {
+	pinner := new(runtime.Pinner)
+	defer pinner.Unpin()
+
+	cSetup := cGoSlice(setup, pinner)
+
+	var cSessionID C.tss_buffer
+	defer C.tss_buffer_free(&cSessionID)
+
+	res := C.dkls_decode_session_id(
+		cSetup,
+		&cSessionID,
+	)
+	if res != 0 {
+		return nil, errors.MapLibError(int(res))
+	}
+
+	sessionID := C.GoBytes(unsafe.Pointer(cSessionID.ptr), C.int(cSessionID.len))
+
+	return sessionID, nil
+}
+
 // DklsDecodeMessage decodes a message from a setup message.
 //
 // Parameters:
@@ -110,3 +145,166 @@ func DklsDecodePartyName(setup []byte, index int) ([]byte, error) {
 
 	return message, nil
 }
+
+// DklsDecodeChainPath decodes the derivation path from a sign setup message.
+//
+// The native library has no decoder for the derivation path, so it is read from
+// the fields of the setup message.
+//
+// Parameters:
+//   - setup: []byte - a byte slice containing the sign setup message.
+//
+// Returns:
+//   - []byte: the derivation path, "m" if the setup message has none.
+//   - error: an error if the setup message can not be decoded.
+func DklsDecodeChainPath(setup []byte) ([]byte, error) {
+	path, err := setupmsg.Value(setup, setupmsg.TagChainPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if path == nil {
+		return []byte("m"), nil
+	}
+
+	return path, nil
+}
+
+// SetupKind is the kind of session a setup message is for.
+type SetupKind string
+
+const (
+	// SetupKindKeygen is the kind of key generation, key refresh and key import
+	// setup messages. A refresh setup message has a key ID, the others have none.
+	// The native library encodes key import setup messages exactly like key
+	// generation ones, so whether a session imports a key has to be agreed on
+	// alongside the setup message; see Setup.IsKeygen and Setup.IsRefresh.
+	SetupKindKeygen SetupKind = "DKG"
+	// SetupKindSign is the kind of full and pre-sign setup messages.
+	SetupKindSign SetupKind = "DSG"
+	// SetupKindFinish is the kind of setup messages finishing a pre-signature.
+	SetupKindFinish SetupKind = "FSG"
+	// SetupKindQC is the kind of quorum change setup messages.
+	SetupKindQC SetupKind = "QC"
+	// SetupKindExport is the kind of key export setup messages.
+	SetupKindExport SetupKind = "EXPORT"
+)
+
+// Setup holds the fields of a setup message. Fields that the kind of setup
+// message does not carry are left empty.
+type Setup struct {
+	// Kind is the kind of session.
+	Kind SetupKind
+	// InstanceID is the random ID of the session instance, unique to the setup message.
+	InstanceID []byte
+	// KeyID is the ID of the key, or for SetupKindFinish the session ID of the
+	// pre-signature, as returned by DklsDecodeKeyID.
+	KeyID []byte
+	// SessionID is the session ID of the setup message, as returned by
+	// DklsDecodeSessionID. The native library reads it from the key ID field, so
+	// it is the key ID, or for SetupKindFinish the session ID of the pre-signature.
+	SessionID []byte
+	// Threshold is the threshold of the key to generate, or of the key after a quorum change.
+	Threshold int
+	// Parties are the names of the parties, in order.
+	Parties []string
+	// Message is the hash of the message to sign.
+	Message []byte
+	// ChainPath is the derivation path of the signing key.
+	ChainPath []byte
+	// PublicKey is the public key of the key of a quorum change.
+	PublicKey []byte
+	// OldParties are the indices in Parties of the holders of the key before a quorum change.
+	OldParties []int
+	// NewParties are the indices in Parties of the holders of the key after a quorum change.
+	NewParties []int
+	// EncryptionKey is the public key the exported key shares are encrypted to.
+	EncryptionKey []byte
+}
+
+// IsRefresh reports whether the setup message is for a key refresh or a key
+// recovery of an existing key: a SetupKindKeygen setup message with a key ID.
+func (s Setup) IsRefresh() bool {
+	return s.Kind == SetupKindKeygen && len(s.KeyID) != 0
+}
+
+// IsKeygen reports whether the setup message is for a key generation or a key
+// import: a SetupKindKeygen setup message without a key ID. The two are encoded
+// alike; a party told to import a key runs DklsKeyImporter on it, otherwise
+// a key generation session.
+func (s Setup) IsKeygen() bool {
+	return s.Kind == SetupKindKeygen && len(s.KeyID) == 0
+}
+
+// DklsDecodeSetup decodes all fields of a setup message, so that a party can
+// inspect a session before joining it.
+//
+// The key ID, the session ID, the message and the party names are read with the
+// native decoders; the fields without a native decoder are read from the
+// fields of the setup message, which go-schnorr encodes alike. Key import setup messages decode like key
+// generation ones, see SetupKindKeygen.
+//
+// Parameters:
+//   - setup: []byte - a byte slice containing the setup message.
+//
+// Returns:
+//   - Setup: the decoded fields of the setup message.
+//   - error: an error if the setup message can not be decoded.
+func DklsDecodeSetup(setup []byte) (Setup, error) {
+	msg, err := setupmsg.Decode(setup)
+	if err != nil {
+		return Setup{}, err
+	}
+
+	decoded := Setup{
+		Kind:          SetupKind(msg.Kind),
+		InstanceID:    msg.InstanceID,
+		Threshold:     msg.Threshold,
+		ChainPath:     msg.ChainPath,
+		PublicKey:     msg.PublicKey,
+		OldParties:    msg.OldParties,
+		NewParties:    msg.NewParties,
+		EncryptionKey: msg.EncryptionKey,
+	}
+
+	switch decoded.Kind {
+	case SetupKindKeygen, SetupKindSign, SetupKindFinish, SetupKindQC, SetupKindExport:
+	default:
+		return Setup{}, fmt.Errorf("%w: unknown setup message kind %q", errors.ErrSerialization, decoded.Kind)
+	}
+
+	if decoded.KeyID, err = decodedField(DklsDecodeKeyID(setup)); err != nil {
+		return Setup{}, err
+	}
+
+	if decoded.SessionID, err = decodedField(DklsDecodeSessionID(setup)); err != nil {
+		return Setup{}, err
+	}
+
+	if decoded.Message, err = decodedField(DklsDecodeMessage(setup)); err != nil {
+		return Setup{}, err
+	}
+
+	decoded.Parties = make([]string, msg.Parties)
+
+	for i := range decoded.Parties {
+		name, err := DklsDecodePartyName(setup, i)
+		if err != nil {
+			return Setup{}, err
+		}
+
+		decoded.Parties[i] = string(name)
+	}
+
+	return decoded, nil
+}
+
+// decodedField returns the result of a native decoder, nil for a field the
+// setup message does not carry.
+func decodedField(value []byte, err error) ([]byte, error) {
+	if err != nil || len(value) == 0 {
+		return nil, err
+	}
+
+	return value, nil
+}
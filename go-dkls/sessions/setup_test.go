@@ -121,3 +121,245 @@ func TestDklsSetup(t *testing.T) {
 		})
 	}
 }
+
+func TestDklsDecodeChainPath(t *testing.T) {
+	t.Parallel()
+
+	keyID := make([]byte, 32)
+	msgHash := make([]byte, 32)
+	ids := testHelper.PrepareIDSlice(2)
+
+	testCases := []struct {
+		name      string
+		chainPath []byte
+		expected  string
+	}{
+		{name: "dkls decode missing chain path", chainPath: nil, expected: "m"},
+		{name: "dkls decode root chain path", chainPath: []byte("m"), expected: "m"},
+		{name: "dkls decode chain path", chainPath: []byte("m/0/1/42"), expected: "m/0/1/42"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			setup, err := session.DklsSignSetupMsgNew(keyID, tc.chainPath, msgHash, ids)
+			assert.NoError(t, err)
+
+			chainPath, err := session.DklsDecodeChainPath(setup)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(chainPath))
+
+			decodedMsg, err := session.DklsDecodeMessage(setup)
+
+			assert.NoError(t, err)
+			assert.Equal(t, msgHash, decodedMsg)
+		})
+	}
+
+	_, err := session.DklsDecodeChainPath([]byte{1, 2, 3})
+	assert.Error(t, err)
+}
+
+func TestDklsDecodeSetup(t *testing.T) {
+	t.Parallel()
+
+	shares, err := testHelper.RunKeygen(2, 3)
+	assert.NoError(t, err)
+
+	keyID, err := session.DklsKeyshareKeyID(shares[0])
+	assert.NoError(t, err)
+
+	publicKey, err := session.DklsKeysharePublicKey(shares[0])
+	assert.NoError(t, err)
+
+	msgHash := make([]byte, 32)
+	for i := range msgHash {
+		msgHash[i] = 1
+	}
+
+	presignID := make([]byte, 32)
+	for i := range presignID {
+		presignID[i] = 2
+	}
+
+	privateKey := make([]byte, 32)
+	privateKey[31] = 1
+
+	testCases := []struct {
+		name     string
+		setup    func() ([]byte, error)
+		expected session.Setup
+	}{
+		{
+			name: "dkls decode keygen setup",
+			setup: func() ([]byte, error) {
+				return session.DklsKeygenSetupMsgNew(2, nil, testHelper.PrepareIDSlice(3))
+			},
+			expected: session.Setup{
+				Kind:      session.SetupKindKeygen,
+				Threshold: 2,
+				Parties:   []string{"p1", "p2", "p3"},
+			},
+		},
+		{
+			name: "dkls decode refresh setup",
+			setup: func() ([]byte, error) {
+				return session.DklsKeygenSetupMsgNew(2, keyID, testHelper.PrepareIDSlice(3))
+			},
+			expected: session.Setup{
+				Kind:      session.SetupKindKeygen,
+				KeyID:     keyID,
+				SessionID: keyID,
+				Threshold: 2,
+				Parties:   []string{"p1", "p2", "p3"},
+			},
+		},
+		{
+			name: "dkls decode sign setup",
+			setup: func() ([]byte, error) {
+				return session.DklsSignSetupMsgNew(keyID, []byte("m/0/1"), msgHash, testHelper.PrepareIDSlice(2))
+			},
+			expected: session.Setup{
+				Kind:      session.SetupKindSign,
+				KeyID:     keyID,
+				SessionID: keyID,
+				Parties:   []string{"p1", "p2"},
+				Message:   msgHash,
+				ChainPath: []byte("m/0/1"),
+			},
+		},
+		{
+			name: "dkls decode finish setup",
+			setup: func() ([]byte, error) {
+				return session.DklsFinishSetupMsgNew(presignID, msgHash, testHelper.PrepareIDSlice(2))
+			},
+			expected: session.Setup{
+				Kind:      session.SetupKindFinish,
+				KeyID:     presignID,
+				SessionID: presignID,
+				Parties:   []string{"p1", "p2"},
+				Message:   msgHash,
+			},
+		},
+		{
+			name: "dkls decode qc setup",
+			setup: func() ([]byte, error) {
+				return session.DklsQcSetupMsgNew(shares[0], 3, []string{"p1", "p2", "p3", "p4"}, []int{0, 1, 2}, []int{0, 1, 3})
+			},
+			expected: session.Setup{
+				Kind:       session.SetupKindQC,
+				KeyID:      keyID,
+				SessionID:  keyID,
+				Threshold:  3,
+				Parties:    []string{"p1", "p2", "p3", "p4"},
+				PublicKey:  publicKey,
+				OldParties: []int{0, 1, 2},
+				NewParties: []int{0, 1, 3},
+			},
+		},
+		{
+			name: "dkls decode import setup",
+			setup: func() ([]byte, error) {
+				_, setup, err := session.DklsKeyImportInitiatorNew(privateKey, nil, 2, []string{"p1", "p2", "p3"})
+
+				return setup, err
+			},
+			expected: session.Setup{
+				Kind:      session.SetupKindKeygen,
+				Threshold: 2,
+				Parties:   []string{"p1", "p2", "p3"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			setup, err := tc.setup()
+			assert.NoError(t, err)
+
+			decoded, err := session.DklsDecodeSetup(setup)
+			assert.NoError(t, err)
+			assert.Len(t, decoded.InstanceID, 32)
+
+			decoded.InstanceID = nil
+			assert.Equal(t, tc.expected, decoded)
+		})
+	}
+
+	t.Run("dkls decode export setup", func(t *testing.T) {
+		t.Parallel()
+
+		_, setup, err := session.DklsKeyExportReceiverNew(shares[0], []string{"p1", "p2"})
+		assert.NoError(t, err)
+
+		decoded, err := session.DklsDecodeSetup(setup)
+
+		assert.NoError(t, err)
+		assert.Equal(t, session.SetupKindExport, decoded.Kind)
+		assert.Equal(t, keyID, decoded.KeyID)
+		assert.Equal(t, keyID, decoded.SessionID)
+		assert.Equal(t, []string{"p1", "p2"}, decoded.Parties)
+		assert.Len(t, decoded.EncryptionKey, 32)
+	})
+
+	t.Run("dkls decode import setup like keygen setup", func(t *testing.T) {
+		t.Parallel()
+
+		keygen, err := session.DklsKeygenSetupMsgNew(2, nil, testHelper.PrepareIDSlice(3))
+		assert.NoError(t, err)
+
+		_, setup, err := session.DklsKeyImportInitiatorNew(privateKey, nil, 2, []string{"p1", "p2", "p3"})
+		assert.NoError(t, err)
+
+		refresh, err := session.DklsKeygenSetupMsgNew(2, keyID, testHelper.PrepareIDSlice(3))
+		assert.NoError(t, err)
+
+		decodedKeygen, err := session.DklsDecodeSetup(keygen)
+		assert.NoError(t, err)
+
+		decodedImport, err := session.DklsDecodeSetup(setup)
+		assert.NoError(t, err)
+
+		decodedRefresh, err := session.DklsDecodeSetup(refresh)
+		assert.NoError(t, err)
+
+		// Only the random instance IDs differ.
+		decodedKeygen.InstanceID, decodedImport.InstanceID = nil, nil
+		assert.Equal(t, decodedKeygen, decodedImport)
+
+		assert.True(t, decodedImport.IsKeygen())
+		assert.False(t, decodedImport.IsRefresh())
+		assert.True(t, decodedRefresh.IsRefresh())
+		assert.False(t, decodedRefresh.IsKeygen())
+	})
+
+	t.Run("dkls decode invalid setup", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := session.DklsDecodeSetup([]byte{1, 2, 3})
+		assert.Error(t, err)
+	})
+}
+
+func TestDklsDecodeSessionID(t *testing.T) {
+	t.Parallel()
+
+	presignID := make([]byte, 32)
+	for i := range presignID {
+		presignID[i] = 2
+	}
+
+	setup, err := session.DklsFinishSetupMsgNew(presignID, make([]byte, 32), testHelper.PrepareIDSlice(2))
+	assert.NoError(t, err)
+
+	sessionID, err := session.DklsDecodeSessionID(setup)
+
+	assert.NoError(t, err)
+	assert.Equal(t, presignID, sessionID)
+}
@@ -30,7 +30,7 @@ import (
 //
 // Parameters:
 //   - keyIDs: []byte - key identifiers used in the signing process.
-//   - chainPath: []byte - chain path.
+//   - chainPath: []byte - chain path, e.g. a DerivationPath; nil signs with the root key.
 //   - messageHash: []byte - a hash of the message to be signed.
 //   - ids: []byte -  IDs of signers.
 //
@@ -38,6 +38,12 @@ import (
 //   - []byte: generated setup message.
 //   - error: An error is returned if the Rust function call fails or if any other issue is encountered.
 func DklsSignSetupMsgNew(keyID []byte, chainPath []byte, messageHash []byte, ids []byte) ([]byte, error) {
+	if len(chainPath) != 0 {
+		if _, err := DerivationPath(chainPath).Indices(); err != nil {
+			return nil, err
+		}
+	}
+
 	pinner := new(runtime.Pinner)
 	defer pinner.Unpin()
 
@@ -0,0 +1,114 @@
+// Provides verification of the ECDSA signatures produced by sign sessions.
+//
+// DklsSignSessionFinish returns a signature as [ R || S || rec-id ]. A
+// signature is checked against the public key of the keyshare, or of the child
+// key at the derivation path it was signed with, and its recovery ID has to
+// recover the same public key.
+//
+// Key functionalities include:
+// - Verifying a signature against a keyshare and an optional derivation path
+// - Verifying a signature against a public key
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+
+	"github.com/vultisig/go-wrappers/go-dkls/errors"
+)
+
+const (
+	// dklsSignatureSize is the size of a signature, [ R || S || rec-id ].
+	dklsSignatureSize = 65
+	// dklsMessageHashSize is the size of the signed message hash.
+	dklsMessageHashSize = 32
+)
+
+// DklsVerifySignature verifies a signature returned by DklsSignSessionFinish
+// against the public key of a keyshare.
+//
+// Parameters:
+//   - share: Handle - a handle representing the keyshare.
+//   - derivationPathStr: []byte - the derivation path the message was signed with,
+//     e.g. a DerivationPath; nil for the root key.
+//   - messageHash: []byte - the 32-byte hash of the signed message.
+//   - signature: []byte - the signature, [ R || S || rec-id ].
+//
+// Returns:
+//   - error: ErrInvalidSignature if the signature or its recovery ID does not match
+//     the public key, or an error if the Rust function call fails.
+func DklsVerifySignature(share Handle, derivationPathStr []byte, messageHash []byte, signature []byte) error {
+	var (
+		publicKey []byte
+		err       error
+	)
+
+	if derivationPathStr == nil {
+		publicKey, err = DklsKeysharePublicKey(share)
+	} else {
+		publicKey, err = DklsKeyshareDeriveChildPublicKey(share, derivationPathStr)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return DklsVerifySignatureWithPublicKey(publicKey, messageHash, signature)
+}
+
+// DklsVerifySignatureWithPublicKey verifies a signature returned by
+// DklsSignSessionFinish against a public key.
+//
+// Parameters:
+//   - publicKey: []byte - the compressed or uncompressed public key.
+//   - messageHash: []byte - the 32-byte hash of the signed message.
+//   - signature: []byte - the signature, [ R || S || rec-id ].
+//
+// Returns:
+//   - error: ErrInvalidSignature if the signature or its recovery ID does not match
+//     the public key.
+func DklsVerifySignatureWithPublicKey(publicKey []byte, messageHash []byte, signature []byte) error {
+	curve := secp256k1.S256()
+
+	var x, y *big.Int
+
+	switch len(publicKey) {
+	case 33:
+		x, y = secp256k1.DecompressPubkey(publicKey)
+	case 65:
+		x, y = curve.Unmarshal(publicKey)
+	}
+
+	if x == nil || !curve.IsOnCurve(x, y) {
+		return fmt.Errorf("%w: invalid public key", errors.ErrInvalidSignature)
+	}
+
+	if len(messageHash) != dklsMessageHashSize {
+		return fmt.Errorf("%w: got a %d-byte message hash, want %d", errors.ErrInvalidSignature, len(messageHash), dklsMessageHashSize)
+	}
+
+	if len(signature) != dklsSignatureSize {
+		return fmt.Errorf("%w: got %d bytes, want %d", errors.ErrInvalidSignature, len(signature), dklsSignatureSize)
+	}
+
+	// Recovery runs in libsecp256k1, which, unlike crypto/ecdsa on a custom
+	// curve, handles every message hash including zero. A signature is valid
+	// if and only if it recovers the public key.
+	expected := curve.Marshal(x, y)
+
+	if recovered, err := secp256k1.RecoverPubkey(messageHash, signature); err == nil && bytes.Equal(recovered, expected) {
+		return nil
+	}
+
+	flipped := bytes.Clone(signature)
+	flipped[64] ^= 1
+
+	if recovered, err := secp256k1.RecoverPubkey(messageHash, flipped); err == nil && bytes.Equal(recovered, expected) {
+		return fmt.Errorf("%w: recovery ID %d does not match the public key", errors.ErrInvalidSignature, signature[64])
+	}
+
+	return fmt.Errorf("%w: signature does not match the public key", errors.ErrInvalidSignature)
+}
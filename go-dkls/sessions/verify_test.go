@@ -0,0 +1,103 @@
+package session_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/vultisig/go-wrappers/go-dkls/errors"
+	session "github.com/vultisig/go-wrappers/go-dkls/sessions"
+	testHelper "github.com/vultisig/go-wrappers/go-dkls/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDklsVerifySignature(t *testing.T) {
+	t.Parallel()
+
+	shares, err := testHelper.RunKeygen(2, 2)
+	assert.NoError(t, err)
+
+	msgHash := sha256.Sum256([]byte("verify me"))
+	chainPath := []byte("m/0/1/42")
+
+	testCases := []struct {
+		name      string
+		chainPath []byte
+	}{
+		{name: "dkls verify root key", chainPath: nil},
+		{name: "dkls verify derived key", chainPath: chainPath},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			signatures, err := testHelper.RunSignWithChainPath(shares, tc.chainPath, msgHash[:])
+			assert.NoError(t, err)
+
+			signature := signatures[0]
+
+			assert.NoError(t, session.DklsVerifySignature(shares[0], tc.chainPath, msgHash[:], signature))
+
+			otherHash := sha256.Sum256([]byte("another message"))
+			err = session.DklsVerifySignature(shares[0], tc.chainPath, otherHash[:], signature)
+			assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+
+			otherPath := []byte("m/7")
+			if tc.chainPath == nil {
+				err = session.DklsVerifySignature(shares[0], otherPath, msgHash[:], signature)
+			} else {
+				err = session.DklsVerifySignature(shares[0], nil, msgHash[:], signature)
+			}
+			assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+
+			corrupted := append([]byte{}, signature...)
+			corrupted[40] ^= 1
+			err = session.DklsVerifySignature(shares[0], tc.chainPath, msgHash[:], corrupted)
+			assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+
+			wrongRecoveryID := append([]byte{}, signature...)
+			wrongRecoveryID[64] ^= 1
+			err = session.DklsVerifySignature(shares[0], tc.chainPath, msgHash[:], wrongRecoveryID)
+			assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+
+			err = session.DklsVerifySignature(shares[0], tc.chainPath, msgHash[:], signature[:64])
+			assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+		})
+	}
+}
+
+func TestDklsVerifySignatureWithPublicKey(t *testing.T) {
+	t.Parallel()
+
+	shares, err := testHelper.RunKeygen(2, 2)
+	assert.NoError(t, err)
+
+	publicKey, err := session.DklsKeysharePublicKey(shares[0])
+	assert.NoError(t, err)
+
+	msgHash := sha256.Sum256([]byte("verify me"))
+
+	signatures, err := testHelper.RunSign(shares, msgHash[:])
+	assert.NoError(t, err)
+
+	assert.NoError(t, session.DklsVerifySignatureWithPublicKey(publicKey, msgHash[:], signatures[0]))
+
+	err = session.DklsVerifySignatureWithPublicKey(publicKey[1:], msgHash[:], signatures[0])
+	assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+
+	err = session.DklsVerifySignatureWithPublicKey(publicKey, msgHash[:31], signatures[0])
+	assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+
+	// A zero hash makes the generator term of the verification the point at infinity.
+	zeroHash := make([]byte, 32)
+
+	signatures, err = testHelper.RunSign(shares, zeroHash)
+	assert.NoError(t, err)
+
+	assert.NoError(t, session.DklsVerifySignatureWithPublicKey(publicKey, zeroHash, signatures[0]))
+
+	err = session.DklsVerifySignatureWithPublicKey(publicKey, msgHash[:], signatures[0])
+	assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+}
@@ -118,6 +118,10 @@ func RunKeygen(t int, n int) ([]session.Handle, error) {
 }
 
 func RunSign(shares []session.Handle, msg []byte) ([][]byte, error) {
+	return RunSignWithChainPath(shares, nil, msg)
+}
+
+func RunSignWithChainPath(shares []session.Handle, chainPath []byte, msg []byte) ([][]byte, error) {
 	t := len(shares)
 
 	keyID, err := session.DklsKeyshareKeyID(shares[0])
@@ -129,7 +133,7 @@ func RunSign(shares []session.Handle, msg []byte) ([][]byte, error) {
 
 	setup, err := session.DklsSignSetupMsgNew(
 		keyID,
-		nil,
+		chainPath,
 		msg,
 		ids,
 	)
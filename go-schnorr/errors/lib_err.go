@@ -5,10 +5,14 @@ package errors
 #include <stdlib.h>
 */
 import "C"
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 var libErrorMessages = map[C.lib_error]string{
 	C.LIB_OK:                          "ok",
+	C.LIB_INVALID_PUBLIC_KEY:          "Invalid public key",
 	C.LIB_INVALID_HANDLE:              "Invalid Handle, not found in the map",
 	C.LIB_HANDLE_IN_USE:               "Handle In Use, the handle is already in use",
 	C.LIB_INVALID_HANDLE_TYPE:         "Invalid Handle Type, the handle is not of the expected type",
@@ -24,6 +28,11 @@ var libErrorMessages = map[C.lib_error]string{
 	C.LIB_SIGNGEN_ERROR:               "Sign generation error",
 	C.LIB_KEYGEN_ERROR:                "Key generation error",
 	C.LIB_KEY_EXPORT_ERROR:            "Key export error",
+	C.LIB_INVALID_THRESHOLD:           "Invalid threshold",
+	C.LIB_INVALID_PARTY_LIST:          "Invalid party list",
+	C.LIB_INVALID_OLD_PARTY_LIST:      "Invalid old party list",
+	C.LIB_INVALID_NEW_PARTY_LIST:      "Invalid new party list",
+	C.LIB_QC_ERROR:                    "Quorum change error",
 	C.LIB_ABORT_PROTOCOL_PARTY_1:      "Protocol abort by party 1",
 	C.LIB_ABORT_PROTOCOL_PARTY_2:      "Protocol abort by party 2",
 	C.LIB_ABORT_PROTOCOL_PARTY_3:      "Protocol abort by party 3",
@@ -36,10 +45,108 @@ var libErrorMessages = map[C.lib_error]string{
 	C.LIB_ABORT_PROTOCOL_PARTY_10:     "Protocol abort by party 10",
 }
 
+// Sentinel values for every error code of the native library.
+// Errors returned by this module match them with errors.Is.
+var (
+	ErrInvalidPublicKey         = newLibError(C.LIB_INVALID_PUBLIC_KEY)
+	ErrInvalidHandle            = newLibError(C.LIB_INVALID_HANDLE)
+	ErrHandleInUse              = newLibError(C.LIB_HANDLE_IN_USE)
+	ErrInvalidHandleType        = newLibError(C.LIB_INVALID_HANDLE_TYPE)
+	ErrNullPtr                  = newLibError(C.LIB_NULL_PTR)
+	ErrInvalidBufferSize        = newLibError(C.LIB_INVALID_BUFFER_SIZE)
+	ErrInvalidSessionState      = newLibError(C.LIB_INVALID_SESSION_STATE)
+	ErrUnknown                  = newLibError(C.LIB_UNKNOWN_ERROR)
+	ErrSerialization            = newLibError(C.LIB_SERIALIZATION_ERROR)
+	ErrInvalidDerivationPathStr = newLibError(C.LIB_INVALID_DERIVATION_PATH_STR)
+	ErrDerivation               = newLibError(C.LIB_DERIVATION_ERROR)
+	ErrSetupMessageValidation   = newLibError(C.LIB_SETUP_MESSAGE_VALIDATION)
+	ErrNonEmptyOutputBuffer     = newLibError(C.LIB_NON_EMPTY_OUTPUT_BUFFER)
+	ErrSigngen                  = newLibError(C.LIB_SIGNGEN_ERROR)
+	ErrKeygen                   = newLibError(C.LIB_KEYGEN_ERROR)
+	ErrKeyExport                = newLibError(C.LIB_KEY_EXPORT_ERROR)
+	ErrInvalidThreshold         = newLibError(C.LIB_INVALID_THRESHOLD)
+	ErrInvalidPartyList         = newLibError(C.LIB_INVALID_PARTY_LIST)
+	ErrInvalidOldPartyList      = newLibError(C.LIB_INVALID_OLD_PARTY_LIST)
+	ErrInvalidNewPartyList      = newLibError(C.LIB_INVALID_NEW_PARTY_LIST)
+	ErrQc                       = newLibError(C.LIB_QC_ERROR)
+	ErrAbortProtocolParty1      = newLibError(C.LIB_ABORT_PROTOCOL_PARTY_1)
+	ErrAbortProtocolParty2      = newLibError(C.LIB_ABORT_PROTOCOL_PARTY_2)
+	ErrAbortProtocolParty3      = newLibError(C.LIB_ABORT_PROTOCOL_PARTY_3)
+	ErrAbortProtocolParty4      = newLibError(C.LIB_ABORT_PROTOCOL_PARTY_4)
+	ErrAbortProtocolParty5      = newLibError(C.LIB_ABORT_PROTOCOL_PARTY_5)
+	ErrAbortProtocolParty6      = newLibError(C.LIB_ABORT_PROTOCOL_PARTY_6)
+	ErrAbortProtocolParty7      = newLibError(C.LIB_ABORT_PROTOCOL_PARTY_7)
+	ErrAbortProtocolParty8      = newLibError(C.LIB_ABORT_PROTOCOL_PARTY_8)
+	ErrAbortProtocolParty9      = newLibError(C.LIB_ABORT_PROTOCOL_PARTY_9)
+	ErrAbortProtocolParty10     = newLibError(C.LIB_ABORT_PROTOCOL_PARTY_10)
+)
+
+// ErrInvalidSignature is returned when a signature does not verify against its
+// public key. It is raised by the Go bindings, not by the native library.
+var ErrInvalidSignature = errors.New("Invalid signature")
+
+// LibError is an error code returned by the native library.
+type LibError struct {
+	// Code is the raw `lib_error` value.
+	Code    int
+	Message string
+}
+
+func newLibError(code C.lib_error) *LibError {
+	return &LibError{
+		Code:    int(code),
+		Message: libErrorMessages[code],
+	}
+}
+
+func (e *LibError) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is a LibError with the same code.
+func (e *LibError) Is(target error) bool {
+	t, ok := target.(*LibError)
+
+	return ok && t.Code == e.Code
+}
+
+// AbortParty returns the zero-based index of the party which caused the protocol
+// to abort, as the party appears in the setup message, and whether the library
+// asks for that party to be banned. The Schnorr library reports plain aborts only,
+// so ban is always false.
+//
+// ok is false if the error is not a protocol abort.
+func (e *LibError) AbortParty() (index int, ban bool, ok bool) {
+	code := C.lib_error(e.Code)
+	if code >= C.LIB_ABORT_PROTOCOL_PARTY_1 && code <= C.LIB_ABORT_PROTOCOL_PARTY_10 {
+		return int(code - C.LIB_ABORT_PROTOCOL_PARTY_1), false, true
+	}
+
+	return 0, false, false
+}
+
+// AbortParty is a shorthand for LibError.AbortParty on any error in err's chain.
+//
+// Parameters:
+//   - err: error - an error returned by this module.
+//
+// Returns:
+//   - int: the zero-based index of the offending party.
+//   - bool: true if the party has to be banned, false for a plain abort.
+//   - bool: false if err is not a protocol abort.
+func AbortParty(err error) (int, bool, bool) {
+	var libErr *LibError
+	if !errors.As(err, &libErr) {
+		return 0, false, false
+	}
+
+	return libErr.AbortParty()
+}
+
 func MapLibError(err int) error {
 	if errMsg, found := libErrorMessages[C.lib_error(err)]; found {
-		return fmt.Errorf(errMsg)
+		return &LibError{Code: err, Message: errMsg}
 	}
 
-	return fmt.Errorf("unknown error: %v", err)
+	return &LibError{Code: err, Message: fmt.Sprintf("unknown error: %v", err)}
 }
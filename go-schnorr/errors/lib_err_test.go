@@ -0,0 +1,73 @@
+package errors_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	liberrors "github.com/vultisig/go-wrappers/go-schnorr/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapLibError(t *testing.T) {
+	err := liberrors.MapLibError(liberrors.ErrSetupMessageValidation.Code)
+
+	assert.True(t, errors.Is(err, liberrors.ErrSetupMessageValidation))
+	assert.False(t, errors.Is(err, liberrors.ErrInvalidHandle))
+	assert.Equal(t, "Setup message vaildation", err.Error())
+
+	wrapped := fmt.Errorf("sign: %w", err)
+	assert.True(t, errors.Is(wrapped, liberrors.ErrSetupMessageValidation))
+
+	var libErr *liberrors.LibError
+	assert.True(t, errors.As(wrapped, &libErr))
+	assert.Equal(t, liberrors.ErrSetupMessageValidation.Code, libErr.Code)
+
+	unknown := liberrors.MapLibError(9999)
+	assert.Equal(t, "unknown error: 9999", unknown.Error())
+}
+
+func TestAbortParty(t *testing.T) {
+	testCases := []struct {
+		name  string
+		err   error
+		index int
+		ban   bool
+		ok    bool
+	}{
+		{
+			name:  "abort by party 1",
+			err:   fmt.Errorf("keygen: %w", liberrors.MapLibError(liberrors.ErrAbortProtocolParty1.Code)),
+			index: 0,
+			ban:   false,
+			ok:    true,
+		},
+		{
+			name:  "abort by party 10",
+			err:   liberrors.ErrAbortProtocolParty10,
+			index: 9,
+			ban:   false,
+			ok:    true,
+		},
+		{
+			name: "not an abort",
+			err:  liberrors.MapLibError(liberrors.ErrSigngen.Code),
+		},
+		{
+			name: "not a library error",
+			err:  errors.New("timeout"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			index, ban, ok := liberrors.AbortParty(tc.err)
+
+			assert.Equal(t, tc.index, index)
+			assert.Equal(t, tc.ban, ban)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
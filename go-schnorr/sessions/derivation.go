@@ -0,0 +1,64 @@
+// Provides a typed BIP32 derivation path for the derive and sign APIs.
+//
+// DerivationPath is validated in Go and holds the canonical string, so it can be
+// passed wherever the bindings take a derivation path or chain path byte slice.
+// It is the same type as the DerivationPath of go-dkls.
+//
+// A threshold key can only derive non-hardened children: a hardened child needs
+// the private key, which no party holds. Since chain paths are validated in Go,
+// SchnorrSignSetupMsgNew rejects a path with hardened segments such as
+// "m/44'/501'/0'/0'" with ErrInvalidDerivationPathStr; the Rust library took it
+// and carried it in the setup message.
+//
+// Key functionalities include:
+// - Parsing and validating derivation path strings
+// - The derivation paths of Vultisig wallets per chain
+package session
+
+import (
+	"github.com/vultisig/go-wrappers/internal/derivation"
+)
+
+// DerivationPath is a validated derivation path of non-hardened indices in its
+// canonical form, e.g. "m/0/1/42". The root path is "m". Its methods report
+// malformed paths with ErrInvalidDerivationPathStr.
+type DerivationPath = derivation.Path
+
+// Chain identifies a chain with a Vultisig derivation path.
+type Chain string
+
+// Vultisig signs on these chains with the root key of the keyshare.
+const (
+	ChainSolana   Chain = "SOL"
+	ChainSui      Chain = "SUI"
+	ChainAptos    Chain = "APT"
+	ChainPolkadot Chain = "DOT"
+	ChainTON      Chain = "TON"
+)
+
+// ParseDerivationPath parses and validates a derivation path string.
+//
+// Parameters:
+//   - derivationPathStr: string - the derivation path, e.g. "m/0/1/42".
+//
+// Returns:
+//   - DerivationPath: the derivation path in canonical form.
+//   - error: ErrInvalidDerivationPathStr if the path is malformed or has hardened segments.
+func ParseDerivationPath(derivationPathStr string) (DerivationPath, error) {
+	return derivation.Parse(derivationPathStr)
+}
+
+// VultisigDerivationPath returns the derivation path of the address of a chain
+// in Vultisig wallets. Vultisig signs on the Ed25519 chains with the root key,
+// so the path is "m" and every chain has the single address index 0.
+//
+// Parameters:
+//   - chain: Chain - the chain.
+//   - index: uint32 - the address index, which is 0.
+//
+// Returns:
+//   - DerivationPath: the derivation path.
+//   - error: ErrInvalidDerivationPathStr if the chain is unknown or the index is not 0.
+func VultisigDerivationPath(chain Chain, index uint32) (DerivationPath, error) {
+	return derivation.Vultisig(derivation.Ed25519, string(chain), index)
+}
@@ -0,0 +1,94 @@
+package session_test
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/vultisig/go-wrappers/go-schnorr/errors"
+	session "github.com/vultisig/go-wrappers/go-schnorr/sessions"
+	testHelper "github.com/vultisig/go-wrappers/go-schnorr/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDerivationPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		path string
+		want string
+		err  bool
+	}{
+		{name: "root", path: "m", want: "m"},
+		{name: "non-hardened path", path: "m/44/501/0/0", want: "m/44/501/0/0"},
+		{name: "leading zeros are canonicalized", path: "m/01", want: "m/1"},
+		{name: "leading plus is canonicalized", path: "m/+1", want: "m/1"},
+		{name: "hardened segments", path: "m/44'/501'/0'/0'", err: true},
+		{name: "missing root", path: "44/501", err: true},
+		{name: "index out of range", path: "m/4294967296", err: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path, err := session.ParseDerivationPath(tc.path)
+			if tc.err {
+				assert.ErrorIs(t, err, errors.ErrInvalidDerivationPathStr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, path.String())
+		})
+	}
+}
+
+func TestVultisigDerivationPathSign(t *testing.T) {
+	t.Parallel()
+
+	// Vultisig signs on the Ed25519 chains with the root key.
+	for _, chain := range []session.Chain{session.ChainSolana, session.ChainSui, session.ChainAptos, session.ChainPolkadot, session.ChainTON} {
+		path, err := session.VultisigDerivationPath(chain, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "m", path.String())
+
+		_, err = session.VultisigDerivationPath(chain, 1)
+		assert.ErrorIs(t, err, errors.ErrInvalidDerivationPathStr)
+	}
+
+	_, err := session.VultisigDerivationPath("ETH", 0)
+	assert.ErrorIs(t, err, errors.ErrInvalidDerivationPathStr)
+
+	path, err := session.VultisigDerivationPath(session.ChainSolana, 0)
+	assert.NoError(t, err)
+
+	shares, err := testHelper.RunSchnorrKeygen(2, 2)
+	assert.NoError(t, err)
+
+	publicKey, err := session.SchnorrKeyshareDeriveChildPublicKey(shares[0], path)
+	assert.NoError(t, err)
+
+	rootKey, err := session.SchnorrKeysharePublicKey(shares[0])
+	assert.NoError(t, err)
+	assert.Equal(t, rootKey, publicKey)
+
+	msg := []byte("signed with a chain derivation path")
+
+	signatures, err := testHelper.RunSchnorrSignWithChainPath(shares, path, msg)
+	assert.NoError(t, err)
+
+	for _, signature := range signatures {
+		assert.True(t, ed25519.Verify(publicKey, msg, signature))
+	}
+
+	keyID, err := session.SchnorrKeyshareKeyID(shares[0])
+	assert.NoError(t, err)
+
+	// The Rust library carried the hardened spelling in the setup message.
+	_, err = session.SchnorrSignSetupMsgNew(keyID, []byte("m/44'/501'"), msg, testHelper.PrepareIDSlice(2))
+	assert.ErrorIs(t, err, errors.ErrInvalidDerivationPathStr)
+}
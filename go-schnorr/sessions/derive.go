@@ -0,0 +1,244 @@
+// Provides non-hardened child key derivation for keyshares.
+//
+// The native library stores a root chain code with every keyshare but offers no
+// derivation, and its sign sessions ignore the derivation path of the setup
+// message. Derivation is therefore done here, following the public child key
+// derivation of BIP32-Ed25519 (Khovratovich and Law, "BIP32-Ed25519:
+// Hierarchical Deterministic Keys over a Non-linear Keyspace", 2017, section V.D),
+// as implemented by the ed25519-bip32 V2 scheme of Cardano:
+//
+//	Z     = HMAC-SHA512(key = c, data = 0x02 || A || ser32le(i))
+//	A_i   = A + [8·parse224le(Z[:28])]B
+//	c_i   = HMAC-SHA512(key = c, data = 0x03 || A || ser32le(i))[32:]
+//
+// where A is the 32-byte compressed public key, c the chain code and B the base
+// point. Adding the same tweak 8·Z_L to every Shamir share of the secret key
+// yields shares of the child secret key, so a derived keyshare signs for the
+// child public key without any interaction between the parties.
+//
+// This is neither SLIP-10, which has no public derivation for Ed25519, nor the
+// derivation of the Vultisig apps, which sign EdDSA with the root key. Keys
+// derived here are specific to this package; TestSchnorrKeyshareDeriveChildVectors
+// pins them to vectors computed with an independent implementation of the paper.
+//
+// Key functionalities include:
+// - Deriving child public keys from derivation path strings such as "m/0/1/42"
+// - Deriving child keyshares which sign for the child public key
+package session
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+
+	"filippo.io/edwards25519"
+
+	"github.com/vultisig/go-wrappers/go-schnorr/errors"
+)
+
+const (
+	// schnorrKeyIDOffset and schnorrKeyIDSize locate the key ID in the serialized keyshare.
+	schnorrKeyIDOffset = schnorrPublicKeyOffset + schnorrPublicKeySize
+	schnorrKeyIDSize   = 32
+	// schnorrReservedOffset locates the reserved byte between the key ID and the
+	// chain code, which is zero in every keyshare of the native library.
+	schnorrReservedOffset = schnorrKeyIDOffset + schnorrKeyIDSize
+	// schnorrChainCodeSize is the size of the chain code, which ends the serialized keyshare.
+	schnorrChainCodeSize = 32
+	// schnorrKeyshareSize is the size of a serialized keyshare: the header, the
+	// secret share, the public key, the key ID, a reserved byte and the chain code.
+	schnorrKeyshareSize = 132
+)
+
+// deriveChild derives the public key, the chain code and the tweak of the secret
+// key of a child key.
+func deriveChild(publicKey []byte, chainCode []byte, path []uint32) ([]byte, []byte, *edwards25519.Scalar, error) {
+	point, err := new(edwards25519.Point).SetBytes(publicKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %v", errors.ErrInvalidPublicKey, err)
+	}
+
+	eight, _ := edwards25519.NewScalar().SetCanonicalBytes(append([]byte{8}, make([]byte, 31)...))
+	tweak := edwards25519.NewScalar()
+
+	for _, index := range path {
+		data := append([]byte{0x02}, point.Bytes()...)
+		data = binary.LittleEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		z := mac.Sum(nil)
+
+		data[0] = 0x03
+
+		mac = hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		chainCode = mac.Sum(nil)[32:]
+
+		// Z_L is at most 2^224, well below the group order, so it is canonical.
+		zl := make([]byte, 32)
+		copy(zl, z[:28])
+
+		t, err := edwards25519.NewScalar().SetCanonicalBytes(zl)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: %v", errors.ErrDerivation, err)
+		}
+
+		t.Multiply(t, eight)
+
+		point.Add(point, new(edwards25519.Point).ScalarBaseMult(t))
+		tweak.Add(tweak, t)
+	}
+
+	if point.Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return nil, nil, nil, fmt.Errorf("%w: child key is the identity", errors.ErrDerivation)
+	}
+
+	return point.Bytes(), chainCode, tweak, nil
+}
+
+// SchnorrKeyshareDeriveChildPublicKey derives a child public key from a keyshare.
+//
+// Parameters:
+//   - share: Handle - a handle representing the keyshare.
+//   - derivationPathStr: []byte - the derivation path, e.g. "m/0/1/42" or a DerivationPath; hardened indices are rejected.
+//
+// Returns:
+//   - []byte: the 32-byte child public key.
+//   - error: an error if the derivation path is invalid or the Rust function call fails.
+func SchnorrKeyshareDeriveChildPublicKey(share Handle, derivationPathStr []byte) ([]byte, error) {
+	path, err := DerivationPath(derivationPathStr).Indices()
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := SchnorrKeysharePublicKey(share)
+	if err != nil {
+		return nil, err
+	}
+
+	chainCode, err := SchnorrKeyshareChainCode(share)
+	if err != nil {
+		return nil, err
+	}
+
+	childPublicKey, _, _, err := deriveChild(publicKey, chainCode, path)
+
+	return childPublicKey, err
+}
+
+// SchnorrKeyshareDeriveChild derives the keyshare of a child key. Signing with
+// the derived keyshares of a threshold of parties produces signatures which
+// verify under the child public key. The derived keyshare keeps the key ID of
+// the root key, so it signs with setup messages created for the root key ID.
+//
+// Every call returns a new keyshare, also for the root path "m", which belongs
+// to the caller; share is left unchanged. The native library has no function
+// releasing keyshares, so the derived keyshare stays allocated until the process
+// exits, and callers should derive a child once and reuse its keyshare.
+//
+// Parameters:
+//   - share: Handle - a handle representing the keyshare.
+//   - derivationPathStr: []byte - the derivation path, e.g. "m/0/1/42" or a DerivationPath; hardened indices are rejected.
+//
+// Returns:
+//   - Handle: a handle representing the derived keyshare.
+//   - error: an error if the derivation path is invalid or the Rust function call fails.
+func SchnorrKeyshareDeriveChild(share Handle, derivationPathStr []byte) (Handle, error) {
+	path, err := DerivationPath(derivationPathStr).Indices()
+	if err != nil {
+		return 0, err
+	}
+
+	return deriveKeyshare(share, path)
+}
+
+// deriveKeyshare creates the native keyshare of a child key from the serialized
+// root keyshare. The serialization carries no version, so its layout is checked
+// by size, by the reserved byte and against the native accessors before the
+// secret share is tweaked. The child keyshare must then serialize to exactly the
+// patched bytes and report the child public key.
+func deriveKeyshare(share Handle, path []uint32) (Handle, error) {
+	buf, err := SchnorrKeyshareToBytes(share)
+	if err != nil {
+		return 0, err
+	}
+
+	defer clear(buf)
+
+	// Only the layout of this size is known; derivation patches it in place.
+	if len(buf) != schnorrKeyshareSize {
+		return 0, fmt.Errorf("%w: unknown keyshare format", errors.ErrSerialization)
+	}
+
+	if _, err := keyshareInfo(buf); err != nil {
+		return 0, err
+	}
+
+	publicKey, err := SchnorrKeysharePublicKey(share)
+	if err != nil {
+		return 0, err
+	}
+
+	keyID, err := SchnorrKeyshareKeyID(share)
+	if err != nil {
+		return 0, err
+	}
+
+	chainCode, err := SchnorrKeyshareChainCode(share)
+	if err != nil {
+		return 0, err
+	}
+
+	chainCodeOffset := len(buf) - schnorrChainCodeSize
+
+	if !bytes.Equal(buf[schnorrPublicKeyOffset:schnorrPublicKeyOffset+schnorrPublicKeySize], publicKey) ||
+		!bytes.Equal(buf[schnorrKeyIDOffset:schnorrKeyIDOffset+schnorrKeyIDSize], keyID) ||
+		buf[schnorrReservedOffset] != 0 ||
+		!bytes.Equal(buf[chainCodeOffset:], chainCode) {
+		return 0, fmt.Errorf("%w: unknown keyshare format", errors.ErrSerialization)
+	}
+
+	childPublicKey, childChainCode, tweak, err := deriveChild(publicKey, chainCode, path)
+	if err != nil {
+		return 0, err
+	}
+
+	secretShare := buf[schnorrSecretShareOffset : schnorrSecretShareOffset+schnorrSecretShareSize]
+
+	secret, err := edwards25519.NewScalar().SetCanonicalBytes(secretShare)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid secret share", errors.ErrSerialization)
+	}
+
+	copy(secretShare, secret.Add(secret, tweak).Bytes())
+	secret.Set(edwards25519.NewScalar())
+
+	copy(buf[schnorrPublicKeyOffset:], childPublicKey)
+	copy(buf[chainCodeOffset:], childChainCode)
+
+	child, err := SchnorrKeyshareFromBytes(buf)
+	if err != nil {
+		return 0, err
+	}
+
+	pk, err := SchnorrKeysharePublicKey(child)
+	if err != nil {
+		return 0, err
+	}
+
+	childBuf, err := SchnorrKeyshareToBytes(child)
+	if err != nil {
+		return 0, err
+	}
+
+	defer clear(childBuf)
+
+	if !bytes.Equal(pk, childPublicKey) || !bytes.Equal(childBuf, buf) {
+		return 0, fmt.Errorf("%w: derived keyshare does not match the child public key", errors.ErrDerivation)
+	}
+
+	return child, nil
+}
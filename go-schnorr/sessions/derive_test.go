@@ -0,0 +1,282 @@
+package session_test
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/vultisig/go-wrappers/go-schnorr/errors"
+	session "github.com/vultisig/go-wrappers/go-schnorr/sessions"
+	testHelper "github.com/vultisig/go-wrappers/go-schnorr/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sharesByIndex orders keyshares by party index.
+func sharesByIndex(t *testing.T, shares []session.Handle) []session.Handle {
+	ordered := make([]session.Handle, len(shares))
+
+	for _, share := range shares {
+		info, err := session.SchnorrKeyshareInfo(share)
+		assert.NoError(t, err)
+
+		ordered[info.PartyIndex] = share
+	}
+
+	return ordered
+}
+
+func TestSchnorrKeyshareDeriveChild(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		input testHelper.TestInput
+		path  string
+	}{
+		{
+			name:  "schnorr derive root 2x2 success",
+			input: testHelper.TestInput{T: 2, N: 2},
+			path:  "m",
+		},
+		{
+			name:  "schnorr derive m/0 2x3 success",
+			input: testHelper.TestInput{T: 2, N: 3},
+			path:  "m/0",
+		},
+		{
+			name:  "schnorr derive m/0/1/42 3x5 success",
+			input: testHelper.TestInput{T: 3, N: 5},
+			path:  "m/0/1/42",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			shares, err := testHelper.RunSchnorrKeygen(tc.input.T, tc.input.N)
+
+			assert.NoError(t, err)
+
+			shares = sharesByIndex(t, shares)
+
+			publicKey, err := session.SchnorrKeysharePublicKey(shares[0])
+			assert.NoError(t, err)
+
+			childPublicKey, err := session.SchnorrKeyshareDeriveChildPublicKey(shares[0], []byte(tc.path))
+
+			assert.NoError(t, err)
+			assert.Len(t, childPublicKey, ed25519.PublicKeySize)
+
+			if tc.path == "m" {
+				assert.Equal(t, publicKey, childPublicKey)
+			} else {
+				assert.NotEqual(t, publicKey, childPublicKey)
+			}
+
+			// every party derives the same child key
+			for _, share := range shares[1:] {
+				pk, err := session.SchnorrKeyshareDeriveChildPublicKey(share, []byte(tc.path))
+
+				assert.NoError(t, err)
+				assert.Equal(t, childPublicKey, pk)
+			}
+
+			child, err := session.SchnorrKeyshareDeriveChild(shares[0], []byte(tc.path))
+			assert.NoError(t, err)
+
+			pk, err := session.SchnorrKeysharePublicKey(child)
+
+			assert.NoError(t, err)
+			assert.Equal(t, childPublicKey, pk)
+
+			msg := []byte("derived signature")
+
+			// the signers derive their keyshares for the chain path of the setup message
+			signatures, err := testHelper.RunSchnorrSignWithChainPath(shares[:tc.input.T], []byte(tc.path), msg)
+
+			assert.NoError(t, err)
+
+			for _, signature := range signatures {
+				assert.True(t, ed25519.Verify(childPublicKey, msg, signature))
+			}
+		})
+	}
+}
+
+func TestSchnorrKeyshareDeriveChildInvalidPath(t *testing.T) {
+	t.Parallel()
+
+	shares, err := testHelper.RunSchnorrKeygen(2, 2)
+
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		path string
+	}{
+		{name: "hardened index", path: "m/44'/501"},
+		{name: "hardened index with h", path: "m/0h"},
+		{name: "missing root", path: "0/1"},
+		{name: "not a number", path: "m/x"},
+		{name: "empty index", path: "m/"},
+		{name: "index out of range", path: "m/2147483648"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := session.SchnorrKeyshareDeriveChildPublicKey(shares[0], []byte(tc.path))
+			assert.ErrorIs(t, err, errors.ErrInvalidDerivationPathStr)
+
+			_, err = session.SchnorrKeyshareDeriveChild(shares[0], []byte(tc.path))
+			assert.ErrorIs(t, err, errors.ErrInvalidDerivationPathStr)
+		})
+	}
+}
+
+// TestSchnorrKeyshareDeriveChildVectors pins the derivation to BIP32-Ed25519
+// public child key derivation. The expected keys and chain codes were computed
+// independently of this package with a reference implementation of the paper.
+func TestSchnorrKeyshareDeriveChildVectors(t *testing.T) {
+	t.Parallel()
+
+	rootChainCode := make([]byte, 32)
+	for i := range rootChainCode {
+		rootChainCode[i] = byte(i)
+	}
+
+	ids := []string{"p1", "p2", "p3"}
+
+	initiator, setup, err := session.SchnorrKeyImportInitiatorNew(genPrivateKey(), rootChainCode, 2, ids)
+	assert.NoError(t, err)
+
+	parties := []P{{Session: initiator, ID: ids[0]}}
+
+	for _, id := range ids[1:] {
+		importer, err := session.SchnorrKeyImporterNew(setup, id)
+		assert.NoError(t, err)
+
+		parties = append(parties, P{Session: importer, ID: id})
+	}
+
+	shares, err := testHelper.RunSchnorrKeygenLoop(parties)
+	assert.NoError(t, err)
+
+	publicKey, err := session.SchnorrKeysharePublicKey(shares[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "cc613540cd8c99fa4647e6e83e969761b17515dbe1896fd0a3e4358ebca65c31", hex.EncodeToString(publicKey))
+
+	testCases := []struct {
+		path      string
+		publicKey string
+		chainCode string
+	}{
+		{
+			path:      "m/0",
+			publicKey: "826ceb5644a879c9a5e558ce53fb5827876279165529bfb6fb4fd6fc4487fe5a",
+			chainCode: "6461d2ee1ea45775408ebb1590aeb9dfcb98c91cfdbf39eb4f15dc18acd1cbfd",
+		},
+		{
+			path:      "m/1",
+			publicKey: "80b7f7e2c080ff5894ac2b5715e983edbe84293e7d37058e909584c8c4cff5a0",
+			chainCode: "01c361645c471c78671ae919bf3e4b96c67d479d60d17347479097831ccb0b79",
+		},
+		{
+			path:      "m/0/1/42",
+			publicKey: "94c6bec54dff92a077c381a264c23cdce0a5cbfd2d3eea35dc07af8e6ca6f03e",
+			chainCode: "5b1e514081fee75ca6613bdd0bd5c92b7344da4286b6d8ed48e67af866abff42",
+		},
+		{
+			path:      "m/2147483647",
+			publicKey: "41846d7b683757ad30e33f9d181f5dcce38453ecddde829612bddcc8466a78a8",
+			chainCode: "6fba32c2ce2edcb419769caf07d072f391adb30ae078634792b6531a395b788c",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.path, func(t *testing.T) {
+			t.Parallel()
+
+			childPublicKey, err := session.SchnorrKeyshareDeriveChildPublicKey(shares[0], []byte(tc.path))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.publicKey, hex.EncodeToString(childPublicKey))
+
+			child, err := session.SchnorrKeyshareDeriveChild(shares[1], []byte(tc.path))
+			assert.NoError(t, err)
+
+			pk, err := session.SchnorrKeysharePublicKey(child)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.publicKey, hex.EncodeToString(pk))
+
+			chainCode, err := session.SchnorrKeyshareChainCode(child)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.chainCode, hex.EncodeToString(chainCode))
+
+			msg := []byte("derived signature")
+
+			signatures, err := testHelper.RunSchnorrSignWithChainPath(shares[:2], []byte(tc.path), msg)
+			assert.NoError(t, err)
+
+			for _, signature := range signatures {
+				assert.True(t, ed25519.Verify(childPublicKey, msg, signature))
+			}
+		})
+	}
+}
+
+// TestSchnorrKeyshareDeriveChildOwnership checks that every derivation returns a
+// new keyshare of the caller and leaves the root keyshare unchanged.
+func TestSchnorrKeyshareDeriveChildOwnership(t *testing.T) {
+	t.Parallel()
+
+	shares, err := testHelper.RunSchnorrKeygen(2, 2)
+	assert.NoError(t, err)
+
+	rootBuf, err := session.SchnorrKeyshareToBytes(shares[0])
+	assert.NoError(t, err)
+
+	root, err := session.SchnorrKeyshareDeriveChild(shares[0], []byte("m"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, shares[0], root)
+
+	buf, err := session.SchnorrKeyshareToBytes(root)
+	assert.NoError(t, err)
+	assert.Equal(t, rootBuf, buf)
+
+	child, err := session.SchnorrKeyshareDeriveChild(shares[0], []byte("m/0/1"))
+	assert.NoError(t, err)
+
+	again, err := session.SchnorrKeyshareDeriveChild(shares[0], []byte("m/0/1"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, child, again)
+
+	childBuf, err := session.SchnorrKeyshareToBytes(child)
+	assert.NoError(t, err)
+
+	buf, err = session.SchnorrKeyshareToBytes(again)
+	assert.NoError(t, err)
+	assert.Equal(t, childBuf, buf)
+
+	buf, err = session.SchnorrKeyshareToBytes(shares[0])
+	assert.NoError(t, err)
+	assert.Equal(t, rootBuf, buf)
+
+	// the root keyshare still signs for the root key
+	publicKey, err := session.SchnorrKeysharePublicKey(shares[0])
+	assert.NoError(t, err)
+
+	msg := []byte("root signature")
+
+	signatures, err := testHelper.RunSchnorrSign(shares, msg)
+	assert.NoError(t, err)
+
+	for _, signature := range signatures {
+		assert.True(t, ed25519.Verify(publicKey, msg, signature))
+	}
+}
@@ -8,6 +8,7 @@
 // - Converting a key share to a byte slice
 // - Retrieving the public key associated with a key share
 // - Obtaining the key ID of a key share
+// - Reading the threshold, party count and party index of a key share
 package session
 
 /*
@@ -16,6 +17,7 @@ package session
 */
 import "C"
 import (
+	"fmt"
 	"runtime"
 	"unsafe"
 
@@ -156,3 +158,75 @@ func SchnorrKeyshareChainCode(share Handle) ([]byte, error) {
 
 	return retBuf, nil
 }
+
+// KeyshareInfo describes the quorum a keyshare belongs to.
+//
+// Party names are not part of a keyshare: the setup message maps party names
+// to indices. The index of a party is its position in the ids list of the
+// keygen or refresh setup message which produced the share, and for a QC its
+// position among the new parties of the setup message.
+type KeyshareInfo struct {
+	// Threshold is the number of parties needed to sign.
+	Threshold int
+	// Parties is the total number of parties.
+	Parties int
+	// PartyIndex is the zero-based index of the owner of the keyshare, as used
+	// by SchnorrQcSetupMsgNew.
+	PartyIndex int
+}
+
+const (
+	// schnorrKeyshareHeaderSize is the size of the serialized keyshare header:
+	// the threshold, the number of parties and the party index.
+	schnorrKeyshareHeaderSize = 3
+	// schnorrSecretShareOffset and schnorrSecretShareSize locate the secret
+	// share, a little-endian Ed25519 scalar, in the serialized keyshare.
+	schnorrSecretShareOffset = schnorrKeyshareHeaderSize
+	schnorrSecretShareSize   = 32
+	// schnorrPublicKeyOffset locates the public key in the serialized keyshare.
+	schnorrPublicKeyOffset = schnorrSecretShareOffset + schnorrSecretShareSize
+	schnorrPublicKeySize   = 32
+)
+
+// SchnorrKeyshareInfo returns the threshold, the number of parties and the
+// party index of a keyshare.
+//
+// The native library has no accessor for these values, so they are read from
+// the header of the serialized keyshare; the rest of the serialization is not
+// inspected.
+//
+// Parameters:
+//   - share: Handle - a handle representing the keyshare.
+//
+// Returns:
+//   - KeyshareInfo: the quorum of the keyshare.
+//   - error: an error if the Rust function call fails or the serialization is not recognized.
+func SchnorrKeyshareInfo(share Handle) (KeyshareInfo, error) {
+	buf, err := SchnorrKeyshareToBytes(share)
+	if err != nil {
+		return KeyshareInfo{}, err
+	}
+
+	defer clear(buf)
+
+	return keyshareInfo(buf)
+}
+
+// keyshareInfo reads the quorum from the header of a serialized keyshare.
+func keyshareInfo(buf []byte) (KeyshareInfo, error) {
+	if len(buf) < schnorrKeyshareHeaderSize {
+		return KeyshareInfo{}, fmt.Errorf("%w: unknown keyshare format", errors.ErrSerialization)
+	}
+
+	info := KeyshareInfo{
+		Threshold:  int(buf[0]),
+		Parties:    int(buf[1]),
+		PartyIndex: int(buf[2]),
+	}
+
+	if info.Threshold == 0 || info.Threshold > info.Parties || info.PartyIndex >= info.Parties {
+		return KeyshareInfo{}, fmt.Errorf("%w: invalid keyshare header", errors.ErrSerialization)
+	}
+
+	return info, nil
+}
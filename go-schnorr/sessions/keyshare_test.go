@@ -102,6 +102,26 @@ func TestSchnorrKeyshare(t *testing.T) {
 			assert.NoError(t, err)
 			assert.NotEmpty(t, keyShare)
 
+			// the serialization format read by SchnorrKeyshareInfo
+			assert.Equal(t, []byte{byte(tc.input.T), byte(tc.input.N)}, keyShareBytes[:2])
+
+			// key share info
+			indices := make(map[int]bool)
+
+			for _, share := range shares {
+				info, err := session.SchnorrKeyshareInfo(share)
+
+				assert.NoError(t, err)
+				assert.Equal(t, tc.input.T, info.Threshold)
+				assert.Equal(t, tc.input.N, info.Parties)
+
+				indices[info.PartyIndex] = true
+			}
+
+			for idx := 0; idx < tc.input.N; idx++ {
+				assert.True(t, indices[idx])
+			}
+
 			// key share public key
 			keySharePublicKey, err := session.SchnorrKeysharePublicKey(shares[0])
 
@@ -214,3 +214,21 @@ func SchnorrQcSessionFinish(session Handle) (Handle, error) {
 
 	return Handle(cKeyshareHandle._0), nil
 }
+
+// SchnorrQcSessionFree deallocates a QC session handler and associated memory.
+//
+// Parameters:
+//   - session: Handle - A handle representing the QC session to be dealocated.
+//
+// Returns:
+//   - error: An error is returned if the Rust function call fails or if any issue occurs during session finalization.
+func SchnorrQcSessionFree(session Handle) error {
+	cSession := cHandle(session)
+
+	res := C.schnorr_qc_session_free(&cSession)
+	if res != 0 {
+		return errors.MapLibError(int(res))
+	}
+
+	return nil
+}
@@ -6,10 +6,12 @@ package session
 */
 import "C"
 import (
+	"fmt"
 	"runtime"
 	"unsafe"
 
 	"github.com/vultisig/go-wrappers/go-schnorr/errors"
+	"github.com/vultisig/go-wrappers/internal/setupmsg"
 )
 
 // SchnorrDecodeKeyId decodes a key ID from a setup message.
@@ -133,3 +135,162 @@ func SchnorrDecodePartyName(setup []byte, index int) ([]byte, error) {
 
 	return message, nil
 }
+
+// SchnorrDecodeChainPath decodes the derivation path from a sign setup message.
+//
+// The native library has no decoder for the derivation path, so it is read from
+// the fields of the setup message.
+//
+// Parameters:
+//   - setup: []byte - a byte slice containing the sign setup message.
+//
+// Returns:
+//   - []byte: the derivation path, "m" if the setup message has none.
+//   - error: an error if the setup message can not be decoded.
+func SchnorrDecodeChainPath(setup []byte) ([]byte, error) {
+	path, err := setupmsg.Value(setup, setupmsg.TagChainPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if path == nil {
+		return []byte("m"), nil
+	}
+
+	return path, nil
+}
+
+// SetupKind is the kind of session a setup message is for.
+type SetupKind string
+
+const (
+	// SetupKindKeygen is the kind of key generation, key refresh and key import
+	// setup messages. A refresh setup message has a key ID, the others have none.
+	// The native library encodes key import setup messages exactly like key
+	// generation ones, so whether a session imports a key has to be agreed on
+	// alongside the setup message; see Setup.IsKeygen and Setup.IsRefresh.
+	SetupKindKeygen SetupKind = "DKG"
+	// SetupKindSign is the kind of sign setup messages.
+	SetupKindSign SetupKind = "DSG"
+	// SetupKindQC is the kind of quorum change setup messages.
+	SetupKindQC SetupKind = "QC"
+	// SetupKindExport is the kind of key export setup messages.
+	SetupKindExport SetupKind = "EXPORT"
+)
+
+// Setup holds the fields of a setup message. Fields that the kind of setup
+// message does not carry are left empty.
+type Setup struct {
+	// Kind is the kind of session.
+	Kind SetupKind
+	// InstanceID is the random ID of the session instance, unique to the setup message.
+	InstanceID []byte
+	// KeyID is the ID of the key, as returned by SchnorrDecodeKeyID.
+	KeyID []byte
+	// SessionID is the session ID of the setup message, as returned by
+	// SchnorrDecodeSessionID; the native library reads it from the key ID field.
+	SessionID []byte
+	// Threshold is the threshold of the key to generate, or of the key after a quorum change.
+	Threshold int
+	// Parties are the names of the parties, in order.
+	Parties []string
+	// Message is the message to sign.
+	Message []byte
+	// ChainPath is the derivation path of the signing key.
+	ChainPath []byte
+	// PublicKey is the public key of the key of a quorum change.
+	PublicKey []byte
+	// OldParties are the indices in Parties of the holders of the key before a quorum change.
+	OldParties []int
+	// NewParties are the indices in Parties of the holders of the key after a quorum change.
+	NewParties []int
+	// EncryptionKey is the public key the exported key shares are encrypted to.
+	EncryptionKey []byte
+}
+
+// IsRefresh reports whether the setup message is for a key refresh or a key
+// recovery of an existing key: a SetupKindKeygen setup message with a key ID.
+func (s Setup) IsRefresh() bool {
+	return s.Kind == SetupKindKeygen && len(s.KeyID) != 0
+}
+
+// IsKeygen reports whether the setup message is for a key generation or a key
+// import: a SetupKindKeygen setup message without a key ID. The two are encoded
+// alike; a party told to import a key runs SchnorrKeyImporterNew on it, otherwise
+// a key generation session.
+func (s Setup) IsKeygen() bool {
+	return s.Kind == SetupKindKeygen && len(s.KeyID) == 0
+}
+
+// SchnorrDecodeSetup decodes all fields of a setup message, so that a party can
+// inspect a session before joining it.
+//
+// The key ID, the session ID, the message and the party names are read with the
+// native decoders; the fields without a native decoder are read from the
+// fields of the setup message, which go-dkls encodes alike. Key import setup messages decode like key
+// generation ones, see SetupKindKeygen.
+//
+// Parameters:
+//   - setup: []byte - a byte slice containing the setup message.
+//
+// Returns:
+//   - Setup: the decoded fields of the setup message.
+//   - error: an error if the setup message can not be decoded.
+func SchnorrDecodeSetup(setup []byte) (Setup, error) {
+	msg, err := setupmsg.Decode(setup)
+	if err != nil {
+		return Setup{}, err
+	}
+
+	decoded := Setup{
+		Kind:          SetupKind(msg.Kind),
+		InstanceID:    msg.InstanceID,
+		Threshold:     msg.Threshold,
+		ChainPath:     msg.ChainPath,
+		PublicKey:     msg.PublicKey,
+		OldParties:    msg.OldParties,
+		NewParties:    msg.NewParties,
+		EncryptionKey: msg.EncryptionKey,
+	}
+
+	switch decoded.Kind {
+	case SetupKindKeygen, SetupKindSign, SetupKindQC, SetupKindExport:
+	default:
+		return Setup{}, fmt.Errorf("%w: unknown setup message kind %q", errors.ErrSerialization, decoded.Kind)
+	}
+
+	if decoded.KeyID, err = decodedField(SchnorrDecodeKeyID(setup)); err != nil {
+		return Setup{}, err
+	}
+
+	if decoded.SessionID, err = decodedField(SchnorrDecodeSessionID(setup)); err != nil {
+		return Setup{}, err
+	}
+
+	if decoded.Message, err = decodedField(SchnorrDecodeMessage(setup)); err != nil {
+		return Setup{}, err
+	}
+
+	decoded.Parties = make([]string, msg.Parties)
+
+	for i := range decoded.Parties {
+		name, err := SchnorrDecodePartyName(setup, i)
+		if err != nil {
+			return Setup{}, err
+		}
+
+		decoded.Parties[i] = string(name)
+	}
+
+	return decoded, nil
+}
+
+// decodedField returns the result of a native decoder, nil for a field the
+// setup message does not carry.
+func decodedField(value []byte, err error) ([]byte, error) {
+	if err != nil || len(value) == 0 {
+		return nil, err
+	}
+
+	return value, nil
+}
@@ -127,3 +127,207 @@ func TestSchnorrSetup(t *testing.T) {
 		})
 	}
 }
+
+func TestSchnorrDecodeChainPath(t *testing.T) {
+	t.Parallel()
+
+	keyID := make([]byte, 32)
+	msg := []byte("message")
+	ids := testHelper.PrepareIDSlice(2)
+
+	testCases := []struct {
+		name      string
+		chainPath []byte
+		expected  string
+	}{
+		{name: "schnorr decode missing chain path", chainPath: nil, expected: "m"},
+		{name: "schnorr decode root chain path", chainPath: []byte("m"), expected: "m"},
+		{name: "schnorr decode chain path", chainPath: []byte("m/0/1/42"), expected: "m/0/1/42"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			setup, err := session.SchnorrSignSetupMsgNew(keyID, tc.chainPath, msg, ids)
+			assert.NoError(t, err)
+
+			chainPath, err := session.SchnorrDecodeChainPath(setup)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(chainPath))
+
+			decodedMsg, err := session.SchnorrDecodeMessage(setup)
+
+			assert.NoError(t, err)
+			assert.Equal(t, msg, decodedMsg)
+		})
+	}
+
+	_, err := session.SchnorrDecodeChainPath([]byte{1, 2, 3})
+	assert.Error(t, err)
+}
+
+func TestSchnorrDecodeSetup(t *testing.T) {
+	t.Parallel()
+
+	shares, err := testHelper.RunSchnorrKeygen(2, 3)
+	assert.NoError(t, err)
+
+	keyID, err := session.SchnorrKeyshareKeyID(shares[0])
+	assert.NoError(t, err)
+
+	publicKey, err := session.SchnorrKeysharePublicKey(shares[0])
+	assert.NoError(t, err)
+
+	msg := []byte("hello")
+
+	privateKey := make([]byte, 32)
+	privateKey[0] = 1
+
+	testCases := []struct {
+		name     string
+		setup    func() ([]byte, error)
+		expected session.Setup
+	}{
+		{
+			name: "schnorr decode keygen setup",
+			setup: func() ([]byte, error) {
+				return session.SchnorrKeygenSetupMsgNew(2, nil, testHelper.PrepareIDSlice(3))
+			},
+			expected: session.Setup{
+				Kind:      session.SetupKindKeygen,
+				Threshold: 2,
+				Parties:   []string{"p1", "p2", "p3"},
+			},
+		},
+		{
+			name: "schnorr decode refresh setup",
+			setup: func() ([]byte, error) {
+				return session.SchnorrKeygenSetupMsgNew(2, keyID, testHelper.PrepareIDSlice(3))
+			},
+			expected: session.Setup{
+				Kind:      session.SetupKindKeygen,
+				KeyID:     keyID,
+				SessionID: keyID,
+				Threshold: 2,
+				Parties:   []string{"p1", "p2", "p3"},
+			},
+		},
+		{
+			name: "schnorr decode sign setup",
+			setup: func() ([]byte, error) {
+				return session.SchnorrSignSetupMsgNew(keyID, []byte("m/0/1"), msg, testHelper.PrepareIDSlice(2))
+			},
+			expected: session.Setup{
+				Kind:      session.SetupKindSign,
+				KeyID:     keyID,
+				SessionID: keyID,
+				Parties:   []string{"p1", "p2"},
+				Message:   msg,
+				ChainPath: []byte("m/0/1"),
+			},
+		},
+		{
+			name: "schnorr decode qc setup",
+			setup: func() ([]byte, error) {
+				return session.SchnorrQcSetupMsgNew(shares[0], 3, []string{"p1", "p2", "p3", "p4"}, []int{0, 1, 2}, []int{0, 1, 3})
+			},
+			expected: session.Setup{
+				Kind:       session.SetupKindQC,
+				KeyID:      keyID,
+				SessionID:  keyID,
+				Threshold:  3,
+				Parties:    []string{"p1", "p2", "p3", "p4"},
+				PublicKey:  publicKey,
+				OldParties: []int{0, 1, 2},
+				NewParties: []int{0, 1, 3},
+			},
+		},
+		{
+			name: "schnorr decode import setup",
+			setup: func() ([]byte, error) {
+				_, setup, err := session.SchnorrKeyImportInitiatorNew(privateKey, nil, 2, []string{"p1", "p2", "p3"})
+
+				return setup, err
+			},
+			expected: session.Setup{
+				Kind:      session.SetupKindKeygen,
+				Threshold: 2,
+				Parties:   []string{"p1", "p2", "p3"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			setup, err := tc.setup()
+			assert.NoError(t, err)
+
+			decoded, err := session.SchnorrDecodeSetup(setup)
+			assert.NoError(t, err)
+			assert.Len(t, decoded.InstanceID, 32)
+
+			decoded.InstanceID = nil
+			assert.Equal(t, tc.expected, decoded)
+		})
+	}
+
+	t.Run("schnorr decode export setup", func(t *testing.T) {
+		t.Parallel()
+
+		_, setup, err := session.SchnorrKeyExportReceiverNew(shares[0], []string{"p1", "p2"})
+		assert.NoError(t, err)
+
+		decoded, err := session.SchnorrDecodeSetup(setup)
+
+		assert.NoError(t, err)
+		assert.Equal(t, session.SetupKindExport, decoded.Kind)
+		assert.Equal(t, keyID, decoded.KeyID)
+		assert.Equal(t, keyID, decoded.SessionID)
+		assert.Equal(t, []string{"p1", "p2"}, decoded.Parties)
+		assert.Len(t, decoded.EncryptionKey, 32)
+	})
+
+	t.Run("schnorr decode import setup like keygen setup", func(t *testing.T) {
+		t.Parallel()
+
+		keygen, err := session.SchnorrKeygenSetupMsgNew(2, nil, testHelper.PrepareIDSlice(3))
+		assert.NoError(t, err)
+
+		_, setup, err := session.SchnorrKeyImportInitiatorNew(privateKey, nil, 2, []string{"p1", "p2", "p3"})
+		assert.NoError(t, err)
+
+		refresh, err := session.SchnorrKeygenSetupMsgNew(2, keyID, testHelper.PrepareIDSlice(3))
+		assert.NoError(t, err)
+
+		decodedKeygen, err := session.SchnorrDecodeSetup(keygen)
+		assert.NoError(t, err)
+
+		decodedImport, err := session.SchnorrDecodeSetup(setup)
+		assert.NoError(t, err)
+
+		decodedRefresh, err := session.SchnorrDecodeSetup(refresh)
+		assert.NoError(t, err)
+
+		// Only the random instance IDs differ.
+		decodedKeygen.InstanceID, decodedImport.InstanceID = nil, nil
+		assert.Equal(t, decodedKeygen, decodedImport)
+
+		assert.True(t, decodedImport.IsKeygen())
+		assert.False(t, decodedImport.IsRefresh())
+		assert.True(t, decodedRefresh.IsRefresh())
+		assert.False(t, decodedRefresh.IsKeygen())
+	})
+
+	t.Run("schnorr decode invalid setup", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := session.SchnorrDecodeSetup([]byte{1, 2, 3})
+		assert.Error(t, err)
+	})
+}
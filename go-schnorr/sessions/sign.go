@@ -29,7 +29,7 @@ import (
 //
 // Parameters:
 //   - keyIDs: []byte - key identifiers used in the signing process.
-//   - chainPath: []byte - chain path.
+//   - chainPath: []byte - derivation path of the signing key, e.g. "m/0/1" or a DerivationPath; nil or "m" signs with the root key.
 //   - messageHash: []byte - a hash of the message to be signed.
 //   - ids: []byte -  IDs of signers.
 //
@@ -37,6 +37,12 @@ import (
 //   - []byte: generated setup message.
 //   - error: An error is returned if the Rust function call fails or if any other issue is encountered.
 func SchnorrSignSetupMsgNew(keyID []byte, chainPath []byte, message []byte, ids []byte) ([]byte, error) {
+	if len(chainPath) != 0 {
+		if _, err := DerivationPath(chainPath).Indices(); err != nil {
+			return nil, err
+		}
+	}
+
 	pinner := new(runtime.Pinner)
 	defer pinner.Unpin()
 
@@ -72,6 +78,11 @@ func SchnorrSignSetupMsgNew(keyID []byte, chainPath []byte, message []byte, ids
 //   - shareOrPresign: Handle - the keyshare or presign handle. Depending on the type of passed handler,
 //     the function will create a different type of session.
 //
+// The native session ignores the derivation path of the setup message and signs
+// for the key of shareOrPresign. To sign for a child key, pass the keyshare of
+// SchnorrKeyshareDeriveChild for the path of the setup message; the signature
+// then verifies under the child public key of SchnorrKeyshareDeriveChildPublicKey.
+//
 // Returns:
 //   - Handle: the handle representing the initialized signing session.
 //   - error: An error is returned if the Rust function call fails or if any other issue is encountered.
@@ -0,0 +1,74 @@
+// Provides verification of the Ed25519 signatures produced by sign sessions.
+//
+// A signature is checked against the public key of the keyshare, or of the
+// child key at the derivation path it was signed with.
+//
+// Key functionalities include:
+// - Verifying a signature against a keyshare and an optional derivation path
+// - Verifying a signature against a public key
+package session
+
+import (
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/vultisig/go-wrappers/go-schnorr/errors"
+)
+
+// SchnorrVerifySignature verifies a signature returned by SchnorrSignSessionFinish
+// against the public key of a keyshare.
+//
+// Parameters:
+//   - share: Handle - a handle representing the keyshare.
+//   - derivationPathStr: []byte - the derivation path the message was signed with,
+//     e.g. a DerivationPath; nil for the root key.
+//   - message: []byte - the signed message.
+//   - signature: []byte - the 64-byte Ed25519 signature.
+//
+// Returns:
+//   - error: ErrInvalidSignature if the signature does not match the public key,
+//     or an error if the Rust function call fails.
+func SchnorrVerifySignature(share Handle, derivationPathStr []byte, message []byte, signature []byte) error {
+	var (
+		publicKey []byte
+		err       error
+	)
+
+	if derivationPathStr == nil {
+		publicKey, err = SchnorrKeysharePublicKey(share)
+	} else {
+		publicKey, err = SchnorrKeyshareDeriveChildPublicKey(share, derivationPathStr)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return SchnorrVerifySignatureWithPublicKey(publicKey, message, signature)
+}
+
+// SchnorrVerifySignatureWithPublicKey verifies a signature returned by
+// SchnorrSignSessionFinish against a public key.
+//
+// Parameters:
+//   - publicKey: []byte - the 32-byte Ed25519 public key.
+//   - message: []byte - the signed message.
+//   - signature: []byte - the 64-byte Ed25519 signature.
+//
+// Returns:
+//   - error: ErrInvalidSignature if the signature does not match the public key.
+func SchnorrVerifySignatureWithPublicKey(publicKey []byte, message []byte, signature []byte) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: got a %d-byte public key, want %d", errors.ErrInvalidSignature, len(publicKey), ed25519.PublicKeySize)
+	}
+
+	if len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: got %d bytes, want %d", errors.ErrInvalidSignature, len(signature), ed25519.SignatureSize)
+	}
+
+	if !ed25519.Verify(publicKey, message, signature) {
+		return fmt.Errorf("%w: signature does not match the public key", errors.ErrInvalidSignature)
+	}
+
+	return nil
+}
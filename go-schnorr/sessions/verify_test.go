@@ -0,0 +1,67 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/vultisig/go-wrappers/go-schnorr/errors"
+	session "github.com/vultisig/go-wrappers/go-schnorr/sessions"
+	testHelper "github.com/vultisig/go-wrappers/go-schnorr/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchnorrVerifySignature(t *testing.T) {
+	t.Parallel()
+
+	shares, err := testHelper.RunSchnorrKeygen(2, 2)
+	assert.NoError(t, err)
+
+	msg := []byte("verify me")
+	chainPath := []byte("m/0/1/42")
+
+	testCases := []struct {
+		name      string
+		chainPath []byte
+	}{
+		{name: "schnorr verify root key", chainPath: nil},
+		{name: "schnorr verify derived key", chainPath: chainPath},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			signatures, err := testHelper.RunSchnorrSignWithChainPath(shares, tc.chainPath, msg)
+			assert.NoError(t, err)
+
+			signature := signatures[0]
+
+			assert.NoError(t, session.SchnorrVerifySignature(shares[0], tc.chainPath, msg, signature))
+
+			err = session.SchnorrVerifySignature(shares[0], tc.chainPath, []byte("another message"), signature)
+			assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+
+			if tc.chainPath == nil {
+				err = session.SchnorrVerifySignature(shares[0], []byte("m/7"), msg, signature)
+			} else {
+				err = session.SchnorrVerifySignature(shares[0], nil, msg, signature)
+			}
+			assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+
+			corrupted := append([]byte{}, signature...)
+			corrupted[10] ^= 1
+			err = session.SchnorrVerifySignature(shares[0], tc.chainPath, msg, corrupted)
+			assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+
+			err = session.SchnorrVerifySignature(shares[0], tc.chainPath, msg, signature[:63])
+			assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+		})
+	}
+
+	publicKey, err := session.SchnorrKeysharePublicKey(shares[0])
+	assert.NoError(t, err)
+
+	err = session.SchnorrVerifySignatureWithPublicKey(publicKey[1:], msg, make([]byte, 64))
+	assert.ErrorIs(t, err, errors.ErrInvalidSignature)
+}
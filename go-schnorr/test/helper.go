@@ -118,6 +118,10 @@ func RunSchnorrKeygen(t int, n int) ([]session.Handle, error) {
 }
 
 func RunSchnorrSign(shares []session.Handle, msg []byte) ([][]byte, error) {
+	return RunSchnorrSignWithChainPath(shares, nil, msg)
+}
+
+func RunSchnorrSignWithChainPath(shares []session.Handle, chainPath []byte, msg []byte) ([][]byte, error) {
 	t := len(shares)
 
 	keyID, err := session.SchnorrKeyshareKeyID(shares[0])
@@ -129,7 +133,7 @@ func RunSchnorrSign(shares []session.Handle, msg []byte) ([][]byte, error) {
 
 	setup, err := session.SchnorrSignSetupMsgNew(
 		keyID,
-		nil,
+		chainPath,
 		msg,
 		ids,
 	)
@@ -143,10 +147,19 @@ func RunSchnorrSign(shares []session.Handle, msg []byte) ([][]byte, error) {
 		id := fmt.Sprintf("p%d", i)
 		bytesID := ([]byte)(id)
 
+		share := shares[i-1]
+
+		// the sign session signs for the key of the keyshare it is given
+		if chainPath != nil {
+			if share, err = session.SchnorrKeyshareDeriveChild(share, chainPath); err != nil {
+				return nil, err
+			}
+		}
+
 		sessionHandle, err := session.SchnorrSignSessionFromSetup(
 			setup,
 			bytesID,
-			shares[i-1],
+			share,
 		)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,171 @@
+// Package derivation holds the derivation path type shared by go-dkls and
+// go-schnorr and the derivation paths of the chains of Vultisig wallets.
+//
+// A threshold key can only derive non-hardened children: a hardened child needs
+// the private key, which no party holds. Paths with hardened segments are
+// therefore rejected rather than silently derived as normal indices. Every other
+// spelling the native libraries derive is accepted, including a leading "+" and
+// leading zeros in a segment, and is canonicalized.
+package derivation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	dklsErrors "github.com/vultisig/go-wrappers/go-dkls/errors"
+	schnorrErrors "github.com/vultisig/go-wrappers/go-schnorr/errors"
+)
+
+// HardenedIndex is the first hardened child index.
+const HardenedIndex = 1 << 31
+
+// Curve is the curve of the keys a derivation path applies to.
+type Curve int
+
+const (
+	Secp256k1 Curve = iota
+	Ed25519
+)
+
+type template struct {
+	curve Curve
+	path  string
+}
+
+// vultisigTemplates are the derivation paths Vultisig wallets use for the
+// chains, with the address index left open.
+//
+// The secp256k1 paths follow the layout of the BIP44 and BIP84 paths of the
+// chains, but every segment is a normal index: Vultisig derives "m/44/60/0/0/i"
+// for Ethereum where BIP44 prescribes "m/44'/60'/0'/0/i". Addresses derived with
+// them differ from the addresses a single-key wallet derives at the BIP44 path.
+//
+// Vultisig signs on the Ed25519 chains with the root key, so they have a single
+// address at the root path "m".
+var vultisigTemplates = map[string]template{
+	"BTC":       {Secp256k1, "m/84/0/0/0/%d"},
+	"LTC":       {Secp256k1, "m/84/2/0/0/%d"},
+	"DOGE":      {Secp256k1, "m/44/3/0/0/%d"},
+	"ETH":       {Secp256k1, "m/44/60/0/0/%d"},
+	"ATOM":      {Secp256k1, "m/44/118/0/0/%d"},
+	"THORChain": {Secp256k1, "m/44/931/0/0/%d"},
+	"TRX":       {Secp256k1, "m/44/195/0/0/%d"},
+	"SOL":       {Ed25519, "m"},
+	"SUI":       {Ed25519, "m"},
+	"APT":       {Ed25519, "m"},
+	"DOT":       {Ed25519, "m"},
+	"TON":       {Ed25519, "m"},
+}
+
+// invalidPathError is an invalid derivation path. It matches the
+// ErrInvalidDerivationPathStr of both go-dkls and go-schnorr, so a Path reports
+// the same error whichever package it is used through.
+type invalidPathError struct {
+	reason string
+}
+
+func invalidf(format string, args ...any) error {
+	return &invalidPathError{reason: fmt.Sprintf(format, args...)}
+}
+
+func (e *invalidPathError) Error() string {
+	return dklsErrors.ErrInvalidDerivationPathStr.Error() + ": " + e.reason
+}
+
+func (e *invalidPathError) Unwrap() []error {
+	return []error{dklsErrors.ErrInvalidDerivationPathStr, schnorrErrors.ErrInvalidDerivationPathStr}
+}
+
+// Path is a derivation path of non-hardened indices, e.g. "m/44/60/0/0/0". The
+// root path is "m". Paths returned by this package are in canonical form; any
+// other byte slice converted to a Path is validated when it is used.
+type Path []byte
+
+// Parse parses and validates a derivation path string and returns it in
+// canonical form.
+func Parse(path string) (Path, error) {
+	indices, err := Path(path).Indices()
+	if err != nil {
+		return nil, err
+	}
+
+	return FromIndices(indices), nil
+}
+
+// FromIndices returns the canonical path of the child indices. The indices must
+// not be hardened.
+func FromIndices(indices []uint32) Path {
+	path := Path("m")
+	for _, index := range indices {
+		path = append(path, '/')
+		path = strconv.AppendUint(path, uint64(index), 10)
+	}
+
+	return path
+}
+
+// Vultisig returns the Vultisig derivation path of an address of a chain.
+// Chains with a single address only have the index 0.
+func Vultisig(curve Curve, chain string, index uint32) (Path, error) {
+	t, ok := vultisigTemplates[chain]
+	if !ok || t.curve != curve {
+		return nil, invalidf("no derivation path for chain %q", chain)
+	}
+
+	if !strings.Contains(t.path, "%d") {
+		if index != 0 {
+			return nil, invalidf("chain %q has a single address", chain)
+		}
+
+		return Parse(t.path)
+	}
+
+	return Parse(fmt.Sprintf(t.path, index))
+}
+
+// Indices returns the child indices of the path.
+func (p Path) Indices() ([]uint32, error) {
+	segments := strings.Split(string(p), "/")
+	if segments[0] != "m" {
+		return nil, invalidf("%q does not start with m", p)
+	}
+
+	indices := make([]uint32, 0, len(segments)-1)
+
+	for _, segment := range segments[1:] {
+		if strings.TrimRight(segment, "'hH") != segment {
+			return nil, invalidf("hardened index %s can not be derived from a threshold key", segment)
+		}
+
+		// The native libraries parse segments as Rust integers, which take a
+		// leading "+" but no "-".
+		index, err := strconv.ParseUint(strings.TrimPrefix(segment, "+"), 10, 32)
+		if err != nil || index >= HardenedIndex {
+			return nil, invalidf("invalid index %q", segment)
+		}
+
+		indices = append(indices, uint32(index))
+	}
+
+	return indices, nil
+}
+
+// Child returns the path of a child of the path.
+func (p Path) Child(index uint32) (Path, error) {
+	indices, err := p.Indices()
+	if err != nil {
+		return nil, err
+	}
+
+	if index >= HardenedIndex {
+		return nil, invalidf("hardened index %d can not be derived from a threshold key", index)
+	}
+
+	return FromIndices(append(indices, index)), nil
+}
+
+// String returns the path as a string.
+func (p Path) String() string {
+	return string(p)
+}
@@ -0,0 +1,130 @@
+package derivation_test
+
+import (
+	"testing"
+
+	dklsErrors "github.com/vultisig/go-wrappers/go-dkls/errors"
+	schnorrErrors "github.com/vultisig/go-wrappers/go-schnorr/errors"
+	"github.com/vultisig/go-wrappers/internal/derivation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		path    string
+		want    string
+		indices []uint32
+		err     bool
+	}{
+		{name: "root", path: "m", want: "m", indices: []uint32{}},
+		{name: "non-hardened path", path: "m/44/60/0/0/0", want: "m/44/60/0/0/0", indices: []uint32{44, 60, 0, 0, 0}},
+		{name: "largest index", path: "m/2147483647", want: "m/2147483647", indices: []uint32{2147483647}},
+		{name: "leading zeros", path: "m/007/1", want: "m/7/1", indices: []uint32{7, 1}},
+		{name: "leading plus", path: "m/+1/2", want: "m/1/2", indices: []uint32{1, 2}},
+		{name: "hardened path", path: "m/44'/60'/0'/0/0", err: true},
+		{name: "hardened segment with h", path: "m/44h/60/0/0/0", err: true},
+		{name: "hardened segment with H", path: "m/44H/60/0/0/0", err: true},
+		{name: "hardened index", path: "m/2147483648", err: true},
+		{name: "index overflow", path: "m/4294967296", err: true},
+		{name: "long index overflow", path: "m/99999999999999999999", err: true},
+		{name: "empty path", path: "", err: true},
+		{name: "empty segment", path: "m//0", err: true},
+		{name: "trailing slash", path: "m/0/", err: true},
+		{name: "only a slash", path: "m/", err: true},
+		{name: "missing root", path: "44/60", err: true},
+		{name: "upper case root", path: "M/0", err: true},
+		{name: "negative index", path: "m/-1", err: true},
+		{name: "double plus", path: "m/++1", err: true},
+		{name: "only a plus", path: "m/+", err: true},
+		{name: "not a number", path: "m/x", err: true},
+		{name: "spaces", path: "m/ 1", err: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path, err := derivation.Parse(tc.path)
+			if tc.err {
+				// the error matches the sentinels of both bindings
+				assert.ErrorIs(t, err, dklsErrors.ErrInvalidDerivationPathStr)
+				assert.ErrorIs(t, err, schnorrErrors.ErrInvalidDerivationPathStr)
+
+				_, err = derivation.Path(tc.path).Indices()
+				assert.ErrorIs(t, err, dklsErrors.ErrInvalidDerivationPathStr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, path.String())
+
+			indices, err := path.Indices()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.indices, indices)
+			assert.Equal(t, path, derivation.FromIndices(indices))
+		})
+	}
+}
+
+func TestChild(t *testing.T) {
+	t.Parallel()
+
+	path, err := derivation.Parse("m/44/60")
+	assert.NoError(t, err)
+
+	child, err := path.Child(7)
+	assert.NoError(t, err)
+	assert.Equal(t, "m/44/60/7", child.String())
+
+	_, err = path.Child(derivation.HardenedIndex)
+	assert.ErrorIs(t, err, dklsErrors.ErrInvalidDerivationPathStr)
+
+	_, err = derivation.Path("m/44'").Child(0)
+	assert.ErrorIs(t, err, schnorrErrors.ErrInvalidDerivationPathStr)
+}
+
+func TestVultisig(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		curve derivation.Curve
+		chain string
+		index uint32
+		want  string
+		err   bool
+	}{
+		{name: "first ethereum address", curve: derivation.Secp256k1, chain: "ETH", index: 0, want: "m/44/60/0/0/0"},
+		{name: "ethereum address index", curve: derivation.Secp256k1, chain: "ETH", index: 3, want: "m/44/60/0/0/3"},
+		{name: "bitcoin", curve: derivation.Secp256k1, chain: "BTC", index: 0, want: "m/84/0/0/0/0"},
+		{name: "solana root key", curve: derivation.Ed25519, chain: "SOL", index: 0, want: "m"},
+		{name: "ton root key", curve: derivation.Ed25519, chain: "TON", index: 0, want: "m"},
+		{name: "solana address index", curve: derivation.Ed25519, chain: "SOL", index: 1, err: true},
+		{name: "hardened address index", curve: derivation.Secp256k1, chain: "ETH", index: derivation.HardenedIndex, err: true},
+		{name: "wrong curve", curve: derivation.Secp256k1, chain: "SOL", index: 0, err: true},
+		{name: "unknown chain", curve: derivation.Secp256k1, chain: "XRP", index: 0, err: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path, err := derivation.Vultisig(tc.curve, tc.chain, tc.index)
+			if tc.err {
+				assert.ErrorIs(t, err, dklsErrors.ErrInvalidDerivationPathStr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, path.String())
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package setupmsg reads the fields of the setup messages of go-dkls and
+// go-schnorr which the native libraries have no decoders for. Both libraries
+// encode setup messages alike.
+//
+// A setup message is made of a 32-byte message ID, a 4-byte TTL, the fields and
+// a 64-byte signature. Each field is encoded as a little-endian 16-bit tag, the
+// little-endian 16-bit length minus one and the value.
+package setupmsg
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	dklsErrors "github.com/vultisig/go-wrappers/go-dkls/errors"
+	schnorrErrors "github.com/vultisig/go-wrappers/go-schnorr/errors"
+)
+
+const (
+	// headerSize is the size of the message ID and the TTL preceding the fields.
+	headerSize = 32 + 4
+	// signatureSize is the size of the signature trailing a setup message.
+	signatureSize = 64
+	// fieldHeaderSize is the size of the tag and the length of a field.
+	fieldHeaderSize = 4
+)
+
+// Tags of the fields of a setup message.
+const (
+	TagKind          uint16 = 0x00
+	TagThreshold     uint16 = 0x01
+	TagInstanceID    uint16 = 0x08
+	TagNewParty      uint16 = 0x14
+	TagOldParty      uint16 = 0x15
+	TagPublicKey     uint16 = 0x16
+	TagChainPath     uint16 = 0x21
+	TagEncryptionKey uint16 = 0x25
+	TagPartyName     uint16 = 0x40
+)
+
+// malformedError is a malformed setup message. It matches the ErrSerialization
+// of both go-dkls and go-schnorr.
+type malformedError struct {
+	reason string
+}
+
+func malformedf(format string, args ...any) error {
+	return &malformedError{reason: fmt.Sprintf(format, args...)}
+}
+
+func (e *malformedError) Error() string {
+	return dklsErrors.ErrSerialization.Error() + ": " + e.reason
+}
+
+func (e *malformedError) Unwrap() []error {
+	return []error{dklsErrors.ErrSerialization, schnorrErrors.ErrSerialization}
+}
+
+// Field is a field of a setup message.
+type Field struct {
+	Tag   uint16
+	Value []byte
+}
+
+// Message holds the fields of a setup message.
+type Message struct {
+	// Kind is the kind of session, e.g. "DKG".
+	Kind string
+	// InstanceID is the random ID of the session instance.
+	InstanceID []byte
+	// Threshold is the threshold of the key to generate, or of the key after a quorum change.
+	Threshold int
+	// Parties is the number of parties.
+	Parties int
+	// ChainPath is the derivation path of the signing key.
+	ChainPath []byte
+	// PublicKey is the public key of the key of a quorum change.
+	PublicKey []byte
+	// OldParties are the indices of the holders of the key before a quorum change.
+	OldParties []int
+	// NewParties are the indices of the holders of the key after a quorum change.
+	NewParties []int
+	// EncryptionKey is the public key the exported key shares are encrypted to.
+	EncryptionKey []byte
+}
+
+// Fields returns the fields of a setup message in order. The values point into
+// setup.
+func Fields(setup []byte) ([]Field, error) {
+	if len(setup) < headerSize+signatureSize {
+		return nil, malformedf("setup message too short")
+	}
+
+	var result []Field
+
+	fields := setup[headerSize : len(setup)-signatureSize]
+
+	for len(fields) > 0 {
+		if len(fields) < fieldHeaderSize {
+			return nil, malformedf("truncated setup message field")
+		}
+
+		tag := binary.LittleEndian.Uint16(fields)
+		size := int(binary.LittleEndian.Uint16(fields[2:])) + 1
+
+		if len(fields) < fieldHeaderSize+size {
+			return nil, malformedf("truncated setup message field")
+		}
+
+		result = append(result, Field{Tag: tag, Value: fields[fieldHeaderSize : fieldHeaderSize+size]})
+		fields = fields[fieldHeaderSize+size:]
+	}
+
+	return result, nil
+}
+
+// Value returns the value of the first field with the given tag of a setup
+// message, or nil if there is none.
+func Value(setup []byte, tag uint16) ([]byte, error) {
+	fields, err := Fields(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		if field.Tag == tag {
+			return field.Value, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Decode decodes the fields of a setup message. It checks that the message has
+// parties and that the party indices of a quorum change are in range, but not
+// the kind, which differs between the libraries.
+func Decode(setup []byte) (Message, error) {
+	fields, err := Fields(setup)
+	if err != nil {
+		return Message{}, err
+	}
+
+	var decoded Message
+
+	for _, field := range fields {
+		switch field.Tag {
+		case TagKind:
+			decoded.Kind = string(field.Value)
+		case TagThreshold:
+			decoded.Threshold = int(field.Value[0])
+		case TagInstanceID:
+			decoded.InstanceID = field.Value
+		case TagPartyName:
+			decoded.Parties++
+		case TagChainPath:
+			decoded.ChainPath = field.Value
+		case TagPublicKey:
+			decoded.PublicKey = field.Value
+		case TagOldParty:
+			decoded.OldParties = append(decoded.OldParties, int(field.Value[0]))
+		case TagNewParty:
+			decoded.NewParties = append(decoded.NewParties, int(field.Value[0]))
+		case TagEncryptionKey:
+			decoded.EncryptionKey = field.Value
+		}
+	}
+
+	if decoded.Parties == 0 {
+		return Message{}, malformedf("setup message without parties")
+	}
+
+	for _, idx := range append(append([]int{}, decoded.OldParties...), decoded.NewParties...) {
+		if idx >= decoded.Parties {
+			return Message{}, malformedf("party index %d out of range", idx)
+		}
+	}
+
+	return decoded, nil
+}
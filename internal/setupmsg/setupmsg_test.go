@@ -0,0 +1,124 @@
+package setupmsg_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	dklsErrors "github.com/vultisig/go-wrappers/go-dkls/errors"
+	schnorrErrors "github.com/vultisig/go-wrappers/go-schnorr/errors"
+	"github.com/vultisig/go-wrappers/internal/setupmsg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// field encodes a field of a setup message.
+func field(tag uint16, value string) []byte {
+	buf := binary.LittleEndian.AppendUint16(nil, tag)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(value)-1))
+
+	return append(buf, value...)
+}
+
+// message encodes a setup message with a zero message ID, TTL and signature.
+func message(fields ...[]byte) []byte {
+	buf := make([]byte, 32+4)
+	for _, f := range fields {
+		buf = append(buf, f...)
+	}
+
+	return append(buf, make([]byte, 64)...)
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	setup := message(
+		field(setupmsg.TagKind, "QC"),
+		field(setupmsg.TagInstanceID, "instance"),
+		field(setupmsg.TagThreshold, "\x02"),
+		field(setupmsg.TagPartyName, "p1"),
+		field(setupmsg.TagPartyName, "p2"),
+		field(setupmsg.TagPartyName, "p3"),
+		field(setupmsg.TagOldParty, "\x00"),
+		field(setupmsg.TagOldParty, "\x01"),
+		field(setupmsg.TagNewParty, "\x02"),
+		field(setupmsg.TagPublicKey, "public key"),
+		field(setupmsg.TagChainPath, "m/0"),
+		field(setupmsg.TagEncryptionKey, "encryption key"),
+		field(0x7f, "unknown fields are skipped"),
+	)
+
+	decoded, err := setupmsg.Decode(setup)
+	assert.NoError(t, err)
+	assert.Equal(t, setupmsg.Message{
+		Kind:          "QC",
+		InstanceID:    []byte("instance"),
+		Threshold:     2,
+		Parties:       3,
+		ChainPath:     []byte("m/0"),
+		PublicKey:     []byte("public key"),
+		OldParties:    []int{0, 1},
+		NewParties:    []int{2},
+		EncryptionKey: []byte("encryption key"),
+	}, decoded)
+
+	path, err := setupmsg.Value(setup, setupmsg.TagChainPath)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("m/0"), path)
+
+	name, err := setupmsg.Value(setup, setupmsg.TagPartyName)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("p1"), name)
+
+	missing, err := setupmsg.Value(message(field(setupmsg.TagPartyName, "p1")), setupmsg.TagChainPath)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	t.Parallel()
+
+	valid := message(field(setupmsg.TagKind, "DKG"), field(setupmsg.TagPartyName, "p1"))
+
+	testCases := []struct {
+		name  string
+		setup []byte
+	}{
+		{name: "empty", setup: nil},
+		{name: "too short", setup: make([]byte, 32+4+63)},
+		{name: "truncated field header", setup: message([]byte{0x40, 0x00, 0x01})},
+		{name: "truncated field value", setup: message(field(setupmsg.TagPartyName, "p1")[:5])},
+		{name: "missing signature", setup: valid[:len(valid)-1]},
+		{name: "no parties", setup: message(field(setupmsg.TagKind, "DKG"))},
+		{
+			name: "old party out of range",
+			setup: message(
+				field(setupmsg.TagPartyName, "p1"),
+				field(setupmsg.TagOldParty, "\x01"),
+			),
+		},
+		{
+			name: "new party out of range",
+			setup: message(
+				field(setupmsg.TagPartyName, "p1"),
+				field(setupmsg.TagNewParty, "\x05"),
+			),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := setupmsg.Decode(tc.setup)
+
+			// the error matches the sentinels of both bindings
+			assert.ErrorIs(t, err, dklsErrors.ErrSerialization)
+			assert.ErrorIs(t, err, schnorrErrors.ErrSerialization)
+		})
+	}
+
+	_, err := setupmsg.Decode(valid)
+	assert.NoError(t, err)
+}
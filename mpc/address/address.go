@@ -0,0 +1,289 @@
+// Package address renders blockchain addresses of DKLS and Schnorr public keys.
+//
+// go-dkls returns secp256k1 public keys, compressed for the root key and
+// uncompressed for derived child keys, and go-schnorr returns Ed25519 public
+// keys. This package turns such a key, or the key of a keyshare for a chain,
+// into the address a wallet displays for it.
+//
+// Key functionalities include:
+// - Ethereum and EVM addresses with EIP-55 checksums
+// - Bitcoin P2PKH, P2WPKH and P2TR key path addresses, also for Litecoin and Dogecoin
+// - Cosmos and THORChain bech32 addresses
+// - Tron addresses
+// - Solana, Sui, Aptos, Polkadot SS58 and TON wallet v4r2 addresses of Ed25519 keys
+// - Deriving the address of a keyshare for a chain
+package address
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+	"golang.org/x/crypto/ripemd160"
+
+	dkls "github.com/vultisig/go-wrappers/go-dkls/sessions"
+)
+
+// ErrPublicKey is returned when a public key is not a valid secp256k1 key.
+var ErrPublicKey = errors.New("address: invalid public key")
+
+// ErrChain is returned when there is no address format for a chain.
+var ErrChain = errors.New("address: unsupported chain")
+
+// Version bytes of base58check P2PKH addresses.
+const (
+	BitcoinP2PKHVersion  byte = 0x00
+	LitecoinP2PKHVersion byte = 0x30
+	DogecoinP2PKHVersion byte = 0x1e
+	tronVersion          byte = 0x41
+)
+
+// Human-readable parts of bech32 addresses.
+const (
+	BitcoinHRP   = "bc"
+	LitecoinHRP  = "ltc"
+	CosmosHRP    = "cosmos"
+	THORChainHRP = "thor"
+)
+
+const (
+	compressedKeySize   = 33
+	uncompressedKeySize = 65
+)
+
+// parsePublicKey parses a compressed or uncompressed secp256k1 public key.
+func parsePublicKey(publicKey []byte) (*ecdsa.PublicKey, error) {
+	var (
+		pub *ecdsa.PublicKey
+		err error
+	)
+
+	switch len(publicKey) {
+	case compressedKeySize:
+		pub, err = crypto.DecompressPubkey(publicKey)
+	case uncompressedKeySize:
+		pub, err = crypto.UnmarshalPubkey(publicKey)
+	default:
+		return nil, fmt.Errorf("%w: got %d bytes, want %d or %d", ErrPublicKey, len(publicKey), compressedKeySize, uncompressedKeySize)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPublicKey, err)
+	}
+
+	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, fmt.Errorf("%w: point is not on the curve", ErrPublicKey)
+	}
+
+	return pub, nil
+}
+
+// compressPublicKey parses a public key and returns its compressed form.
+func compressPublicKey(publicKey []byte) ([]byte, error) {
+	pub, err := parsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.CompressPubkey(pub), nil
+}
+
+// hash160 returns RIPEMD-160(SHA-256(data)).
+func hash160(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	h := ripemd160.New()
+	h.Write(sum[:])
+
+	return h.Sum(nil)
+}
+
+// Ethereum returns the EIP-55 checksummed address of a public key, valid for
+// Ethereum and all EVM chains.
+//
+// Parameters:
+//   - publicKey: []byte - the compressed or uncompressed public key.
+//
+// Returns:
+//   - string: the address, e.g. "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf".
+//   - error: ErrPublicKey if the public key is invalid.
+func Ethereum(publicKey []byte) (string, error) {
+	pub, err := parsePublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return crypto.PubkeyToAddress(*pub).Hex(), nil
+}
+
+// Tron returns the base58check address of a public key on Tron.
+//
+// Parameters:
+//   - publicKey: []byte - the compressed or uncompressed public key.
+//
+// Returns:
+//   - string: the address, starting with "T".
+//   - error: ErrPublicKey if the public key is invalid.
+func Tron(publicKey []byte) (string, error) {
+	pub, err := parsePublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return base58Check(tronVersion, crypto.PubkeyToAddress(*pub).Bytes()), nil
+}
+
+// P2PKH returns the base58check pay-to-public-key-hash address of a public key.
+//
+// Parameters:
+//   - publicKey: []byte - the compressed or uncompressed public key.
+//   - version: byte - the version byte of the network, e.g. BitcoinP2PKHVersion.
+//
+// Returns:
+//   - string: the address.
+//   - error: ErrPublicKey if the public key is invalid.
+func P2PKH(publicKey []byte, version byte) (string, error) {
+	publicKey, err := compressPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return base58Check(version, hash160(publicKey)), nil
+}
+
+// P2WPKH returns the bech32 pay-to-witness-public-key-hash address of a public key.
+//
+// Parameters:
+//   - publicKey: []byte - the compressed or uncompressed public key.
+//   - hrp: string - the human-readable part of the network, e.g. BitcoinHRP.
+//
+// Returns:
+//   - string: the address.
+//   - error: ErrPublicKey if the public key is invalid.
+func P2WPKH(publicKey []byte, hrp string) (string, error) {
+	publicKey, err := compressPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return segwitAddress(hrp, 0, hash160(publicKey))
+}
+
+// P2TR returns the bech32m pay-to-taproot address spendable by the key path
+// of a public key, without a script tree as specified by BIP-86.
+//
+// Parameters:
+//   - publicKey: []byte - the compressed or uncompressed internal public key.
+//   - hrp: string - the human-readable part of the network, e.g. BitcoinHRP.
+//
+// Returns:
+//   - string: the address.
+//   - error: ErrPublicKey if the public key is invalid.
+func P2TR(publicKey []byte, hrp string) (string, error) {
+	pub, err := parsePublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	curve := secp256k1.S256()
+
+	// The internal key is the point with the x coordinate of the public key
+	// and an even y coordinate.
+	px, py := pub.X, new(big.Int).Set(pub.Y)
+	if py.Bit(0) == 1 {
+		py.Sub(curve.P, py)
+	}
+
+	xOnly := px.FillBytes(make([]byte, 32))
+
+	tag := sha256.Sum256([]byte("TapTweak"))
+	tweak := sha256.Sum256(append(append(tag[:], tag[:]...), xOnly...))
+
+	if new(big.Int).SetBytes(tweak[:]).Cmp(curve.N) >= 0 {
+		return "", fmt.Errorf("%w: taproot tweak out of range", ErrPublicKey)
+	}
+
+	tx, ty := curve.ScalarBaseMult(tweak[:])
+	qx, _ := curve.Add(px, py, tx, ty)
+
+	return segwitAddress(hrp, 1, qx.FillBytes(make([]byte, 32)))
+}
+
+// Cosmos returns the bech32 account address of a public key on a Cosmos SDK chain.
+//
+// Parameters:
+//   - publicKey: []byte - the compressed or uncompressed public key.
+//   - hrp: string - the human-readable part of the chain, e.g. CosmosHRP or THORChainHRP.
+//
+// Returns:
+//   - string: the address.
+//   - error: ErrPublicKey if the public key is invalid.
+func Cosmos(publicKey []byte, hrp string) (string, error) {
+	publicKey, err := compressPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return bech32Encode(hrp, convertBits(hash160(publicKey)), bech32Const), nil
+}
+
+// FromPublicKey returns the default address of a public key on a chain:
+// P2WPKH for Bitcoin and Litecoin, P2PKH for Dogecoin, EIP-55 for Ethereum,
+// bech32 for Cosmos and THORChain and base58check for Tron.
+//
+// Parameters:
+//   - chain: dkls.Chain - the chain.
+//   - publicKey: []byte - the compressed or uncompressed public key.
+//
+// Returns:
+//   - string: the address.
+//   - error: ErrChain if the chain is unknown, ErrPublicKey if the public key is invalid.
+func FromPublicKey(chain dkls.Chain, publicKey []byte) (string, error) {
+	switch chain {
+	case dkls.ChainBitcoin:
+		return P2WPKH(publicKey, BitcoinHRP)
+	case dkls.ChainLitecoin:
+		return P2WPKH(publicKey, LitecoinHRP)
+	case dkls.ChainDogecoin:
+		return P2PKH(publicKey, DogecoinP2PKHVersion)
+	case dkls.ChainEthereum:
+		return Ethereum(publicKey)
+	case dkls.ChainCosmos:
+		return Cosmos(publicKey, CosmosHRP)
+	case dkls.ChainTHORChain:
+		return Cosmos(publicKey, THORChainHRP)
+	case dkls.ChainTron:
+		return Tron(publicKey)
+	default:
+		return "", fmt.Errorf("%w: %q", ErrChain, chain)
+	}
+}
+
+// FromKeyshare derives the child key of a keyshare at the Vultisig derivation
+// path of a chain, see dkls.VultisigDerivationPath, and returns its default
+// address on the chain.
+//
+// Parameters:
+//   - share: dkls.Handle - a handle representing the keyshare.
+//   - chain: dkls.Chain - the chain.
+//   - index: uint32 - the address index of the derivation path.
+//
+// Returns:
+//   - string: the address.
+//   - error: an error if the chain is unknown or the derivation fails.
+func FromKeyshare(share dkls.Handle, chain dkls.Chain, index uint32) (string, error) {
+	path, err := dkls.VultisigDerivationPath(chain, index)
+	if err != nil {
+		return "", err
+	}
+
+	publicKey, err := dkls.DklsKeyshareDeriveChildPublicKey(share, path)
+	if err != nil {
+		return "", err
+	}
+
+	return FromPublicKey(chain, publicKey)
+}
@@ -0,0 +1,189 @@
+package address_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	dkls "github.com/vultisig/go-wrappers/go-dkls/sessions"
+	testHelper "github.com/vultisig/go-wrappers/go-dkls/test"
+	"github.com/vultisig/go-wrappers/mpc/address"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	assert.NoError(t, err)
+
+	return b
+}
+
+// generatorKey is the public key of the private key 1.
+const (
+	generatorKey             = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	generatorKeyUncompressed = "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798" +
+		"483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+)
+
+func TestFormats(t *testing.T) {
+	t.Parallel()
+
+	generator := decodeHex(t, generatorKey)
+	// bip84Key is the key at m/84'/0'/0'/0/0 of the BIP-84 test vector wallet.
+	bip84Key := decodeHex(t, "0330d54fd0dd420a6e5f8d3624f5f3482cae350f79d5f0753bf5beef9c2d91af3c")
+	// bip86Key is the internal key at m/86'/0'/0'/0/0 of the BIP-86 test vector wallet.
+	bip86Key := decodeHex(t, "02cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115")
+
+	testCases := []struct {
+		name   string
+		encode func() (string, error)
+		want   string
+	}{
+		{
+			name:   "ethereum",
+			encode: func() (string, error) { return address.Ethereum(generator) },
+			want:   "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+		},
+		{
+			name:   "bitcoin p2pkh",
+			encode: func() (string, error) { return address.P2PKH(generator, address.BitcoinP2PKHVersion) },
+			want:   "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+		},
+		{
+			name:   "bitcoin p2wpkh",
+			encode: func() (string, error) { return address.P2WPKH(generator, address.BitcoinHRP) },
+			want:   "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+		},
+		{
+			name:   "bip84 p2wpkh",
+			encode: func() (string, error) { return address.P2WPKH(bip84Key, address.BitcoinHRP) },
+			want:   "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+		},
+		{
+			name:   "bip86 p2tr",
+			encode: func() (string, error) { return address.P2TR(bip86Key, address.BitcoinHRP) },
+			want:   "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+		},
+		{
+			name:   "litecoin p2wpkh",
+			encode: func() (string, error) { return address.P2WPKH(generator, address.LitecoinHRP) },
+			want:   "ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9",
+		},
+		{
+			name:   "dogecoin p2pkh",
+			encode: func() (string, error) { return address.P2PKH(generator, address.DogecoinP2PKHVersion) },
+			want:   "DFpN6QqFfUm3gKNaxN6tNcab1FArL9cZLE",
+		},
+		{
+			name:   "cosmos",
+			encode: func() (string, error) { return address.Cosmos(generator, address.CosmosHRP) },
+			want:   "cosmos1w508d6qejxtdg4y5r3zarvary0c5xw7k6ah60c",
+		},
+		{
+			name:   "thorchain",
+			encode: func() (string, error) { return address.Cosmos(generator, address.THORChainHRP) },
+			want:   "thor1w508d6qejxtdg4y5r3zarvary0c5xw7ku6wp68",
+		},
+		{
+			name:   "tron",
+			encode: func() (string, error) { return address.Tron(generator) },
+			want:   "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tc.encode()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestUncompressedPublicKey(t *testing.T) {
+	t.Parallel()
+
+	compressed := decodeHex(t, generatorKey)
+	uncompressed := decodeHex(t, generatorKeyUncompressed)
+
+	for _, chain := range []dkls.Chain{dkls.ChainBitcoin, dkls.ChainDogecoin, dkls.ChainEthereum, dkls.ChainCosmos, dkls.ChainTron} {
+		want, err := address.FromPublicKey(chain, compressed)
+		assert.NoError(t, err)
+
+		got, err := address.FromPublicKey(chain, uncompressed)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	want, err := address.P2TR(compressed, address.BitcoinHRP)
+	assert.NoError(t, err)
+
+	got, err := address.P2TR(uncompressed, address.BitcoinHRP)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestInvalidPublicKey(t *testing.T) {
+	t.Parallel()
+
+	badPrefix := decodeHex(t, generatorKey)
+	badPrefix[0] = 0x05
+
+	notOnCurve := decodeHex(t, generatorKeyUncompressed)
+	notOnCurve[64] ^= 1
+
+	for _, publicKey := range [][]byte{nil, decodeHex(t, generatorKey)[:32], badPrefix, notOnCurve} {
+		_, err := address.FromPublicKey(dkls.ChainBitcoin, publicKey)
+		assert.ErrorIs(t, err, address.ErrPublicKey)
+
+		_, err = address.Ethereum(publicKey)
+		assert.ErrorIs(t, err, address.ErrPublicKey)
+
+		_, err = address.P2TR(publicKey, address.BitcoinHRP)
+		assert.ErrorIs(t, err, address.ErrPublicKey)
+	}
+
+	_, err := address.FromPublicKey("XRP", decodeHex(t, generatorKey))
+	assert.ErrorIs(t, err, address.ErrChain)
+}
+
+func TestFromKeyshare(t *testing.T) {
+	t.Parallel()
+
+	shares, err := testHelper.RunKeygen(2, 3)
+	assert.NoError(t, err)
+
+	chains := []dkls.Chain{
+		dkls.ChainBitcoin,
+		dkls.ChainLitecoin,
+		dkls.ChainDogecoin,
+		dkls.ChainEthereum,
+		dkls.ChainCosmos,
+		dkls.ChainTHORChain,
+		dkls.ChainTron,
+	}
+
+	for _, chain := range chains {
+		path, err := dkls.VultisigDerivationPath(chain, 1)
+		assert.NoError(t, err)
+
+		publicKey, err := dkls.DklsKeyshareDeriveChildPublicKey(shares[0], path)
+		assert.NoError(t, err)
+
+		want, err := address.FromPublicKey(chain, publicKey)
+		assert.NoError(t, err)
+
+		// All parties render the same address.
+		for _, share := range shares {
+			got, err := address.FromKeyshare(share, chain, 1)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package address
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+
+	schnorr "github.com/vultisig/go-wrappers/go-schnorr/sessions"
+)
+
+// SS58 network prefixes of Polkadot addresses.
+const (
+	PolkadotSS58Prefix  uint16 = 0
+	KusamaSS58Prefix    uint16 = 2
+	SubstrateSS58Prefix uint16 = 42
+)
+
+const (
+	// suiEd25519Flag is the signature scheme flag of Ed25519 keys on Sui.
+	suiEd25519Flag byte = 0x00
+	// aptosEd25519Scheme is the authentication key scheme of single Ed25519 keys on Aptos.
+	aptosEd25519Scheme byte = 0x00
+)
+
+// TON wallet v4r2 parameters. A TON address is the hash of the initial state of
+// the wallet contract, which is made of the wallet code and of a data cell
+// holding the public key.
+const (
+	tonV4R2CodeHash          = "feb5ff6820e2ff0d9483e7e0d62c817d846789fb4ae580c878866d959dabd5c0"
+	tonV4R2CodeDepth         = 7
+	tonDefaultSubWallet      = 698983191
+	tonBounceableTag    byte = 0x11
+	tonNonBounceableTag byte = 0x51
+)
+
+// checkEd25519PublicKey checks the length of an Ed25519 public key.
+func checkEd25519PublicKey(publicKey []byte) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: got %d bytes, want %d", ErrPublicKey, len(publicKey), ed25519.PublicKeySize)
+	}
+
+	return nil
+}
+
+// Solana returns the base58 address of an Ed25519 public key on Solana.
+//
+// Parameters:
+//   - publicKey: []byte - the 32-byte Ed25519 public key.
+//
+// Returns:
+//   - string: the address.
+//   - error: ErrPublicKey if the public key is invalid.
+func Solana(publicKey []byte) (string, error) {
+	if err := checkEd25519PublicKey(publicKey); err != nil {
+		return "", err
+	}
+
+	return base58Encode(publicKey), nil
+}
+
+// Sui returns the address of an Ed25519 public key on Sui, the BLAKE2b-256
+// hash of the scheme flag and the public key.
+//
+// Parameters:
+//   - publicKey: []byte - the 32-byte Ed25519 public key.
+//
+// Returns:
+//   - string: the 0x-prefixed hex address.
+//   - error: ErrPublicKey if the public key is invalid.
+func Sui(publicKey []byte) (string, error) {
+	if err := checkEd25519PublicKey(publicKey); err != nil {
+		return "", err
+	}
+
+	sum := blake2b.Sum256(append([]byte{suiEd25519Flag}, publicKey...))
+
+	return "0x" + hex.EncodeToString(sum[:]), nil
+}
+
+// Aptos returns the address of an Ed25519 public key on Aptos, the SHA3-256
+// authentication key of the public key followed by the scheme byte.
+//
+// Parameters:
+//   - publicKey: []byte - the 32-byte Ed25519 public key.
+//
+// Returns:
+//   - string: the 0x-prefixed hex address.
+//   - error: ErrPublicKey if the public key is invalid.
+func Aptos(publicKey []byte) (string, error) {
+	if err := checkEd25519PublicKey(publicKey); err != nil {
+		return "", err
+	}
+
+	sum := sha3.Sum256(append(append([]byte{}, publicKey...), aptosEd25519Scheme))
+
+	return "0x" + hex.EncodeToString(sum[:]), nil
+}
+
+// SS58 returns the SS58 address of an Ed25519 public key on a Substrate network.
+//
+// Parameters:
+//   - publicKey: []byte - the 32-byte Ed25519 public key.
+//   - prefix: uint16 - the network prefix, e.g. PolkadotSS58Prefix; at most 16383.
+//
+// Returns:
+//   - string: the address.
+//   - error: ErrPublicKey if the public key is invalid, or an error if the prefix is out of range.
+func SS58(publicKey []byte, prefix uint16) (string, error) {
+	if err := checkEd25519PublicKey(publicKey); err != nil {
+		return "", err
+	}
+
+	var data []byte
+
+	switch {
+	case prefix < 64:
+		data = []byte{byte(prefix)}
+	case prefix < 16384:
+		data = []byte{
+			byte((prefix&0xfc)>>2) | 0x40,
+			byte(prefix>>8) | byte((prefix&0x03)<<6),
+		}
+	default:
+		return "", fmt.Errorf("address: invalid SS58 prefix %d", prefix)
+	}
+
+	data = append(data, publicKey...)
+
+	h, _ := blake2b.New512(nil)
+	h.Write([]byte("SS58PRE"))
+	h.Write(data)
+
+	return base58Encode(append(data, h.Sum(nil)[:2]...)), nil
+}
+
+// tonAccountID returns the hash of the initial state of a v4r2 wallet of an
+// Ed25519 public key, the account ID of the wallet in its workchain.
+func tonAccountID(publicKey []byte) []byte {
+	// The data cell holds seqno:uint32, subwallet_id:uint32, public_key:bits256
+	// and an empty plugins dictionary, a single 0 bit. The serialization of its
+	// 321 bits is completed with a 1 bit and zero padding.
+	data := make([]byte, 0, 2+41)
+	data = append(data, 0, 81)
+	data = binary.BigEndian.AppendUint32(data, 0)
+	data = binary.BigEndian.AppendUint32(data, tonDefaultSubWallet)
+	data = append(data, publicKey...)
+	data = append(data, 0x40)
+	dataHash := sha256.Sum256(data)
+
+	codeHash, _ := hex.DecodeString(tonV4R2CodeHash)
+
+	// The state init cell holds the bits 00110 (no split depth, no special,
+	// code, data, no library) and references the code and data cells.
+	stateInit := []byte{2, 1, 0x34}
+	stateInit = binary.BigEndian.AppendUint16(stateInit, tonV4R2CodeDepth)
+	stateInit = binary.BigEndian.AppendUint16(stateInit, 0)
+	stateInit = append(stateInit, codeHash...)
+	stateInit = append(stateInit, dataHash[:]...)
+	sum := sha256.Sum256(stateInit)
+
+	return sum[:]
+}
+
+// TON returns the user-friendly address of the v4r2 wallet of an Ed25519
+// public key in the basechain.
+//
+// Parameters:
+//   - publicKey: []byte - the 32-byte Ed25519 public key.
+//   - bounceable: bool - whether the address is bounceable ("EQ...") or not ("UQ...").
+//
+// Returns:
+//   - string: the URL-safe base64 address.
+//   - error: ErrPublicKey if the public key is invalid.
+func TON(publicKey []byte, bounceable bool) (string, error) {
+	if err := checkEd25519PublicKey(publicKey); err != nil {
+		return "", err
+	}
+
+	tag := tonNonBounceableTag
+	if bounceable {
+		tag = tonBounceableTag
+	}
+
+	// The tag is followed by the workchain, 0 for the basechain.
+	data := append([]byte{tag, 0}, tonAccountID(publicKey)...)
+	data = binary.BigEndian.AppendUint16(data, crc16(data))
+
+	return base64.URLEncoding.EncodeToString(data), nil
+}
+
+// FromEd25519PublicKey returns the default address of an Ed25519 public key on
+// a chain: SS58 with the Polkadot prefix for Polkadot and the non-bounceable
+// v4r2 wallet address for TON.
+//
+// Parameters:
+//   - chain: schnorr.Chain - the chain.
+//   - publicKey: []byte - the 32-byte Ed25519 public key.
+//
+// Returns:
+//   - string: the address.
+//   - error: ErrChain if the chain is unknown, ErrPublicKey if the public key is invalid.
+func FromEd25519PublicKey(chain schnorr.Chain, publicKey []byte) (string, error) {
+	switch chain {
+	case schnorr.ChainSolana:
+		return Solana(publicKey)
+	case schnorr.ChainSui:
+		return Sui(publicKey)
+	case schnorr.ChainAptos:
+		return Aptos(publicKey)
+	case schnorr.ChainPolkadot:
+		return SS58(publicKey, PolkadotSS58Prefix)
+	case schnorr.ChainTON:
+		return TON(publicKey, false)
+	default:
+		return "", fmt.Errorf("%w: %q", ErrChain, chain)
+	}
+}
+
+// FromSchnorrKeyshare returns the default address of a Schnorr keyshare on a
+// chain. The address is of the root key, or of the child key at a derivation path.
+//
+// Parameters:
+//   - share: schnorr.Handle - a handle representing the keyshare.
+//   - chain: schnorr.Chain - the chain.
+//   - path: schnorr.DerivationPath - the derivation path of the child key, or nil for the root key.
+//
+// Returns:
+//   - string: the address.
+//   - error: an error if the chain is unknown or the Rust function call fails.
+func FromSchnorrKeyshare(share schnorr.Handle, chain schnorr.Chain, path schnorr.DerivationPath) (string, error) {
+	var (
+		publicKey []byte
+		err       error
+	)
+
+	if path == nil {
+		publicKey, err = schnorr.SchnorrKeysharePublicKey(share)
+	} else {
+		publicKey, err = schnorr.SchnorrKeyshareDeriveChildPublicKey(share, path)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return FromEd25519PublicKey(chain, publicKey)
+}
@@ -0,0 +1,178 @@
+package address_test
+
+import (
+	"crypto/sha512"
+	"testing"
+
+	schnorr "github.com/vultisig/go-wrappers/go-schnorr/sessions"
+	schnorrHelper "github.com/vultisig/go-wrappers/go-schnorr/test"
+	"github.com/vultisig/go-wrappers/mpc/address"
+
+	"filippo.io/edwards25519"
+	"github.com/stretchr/testify/assert"
+)
+
+// rfc8032Key is the public key of test 1 of RFC 8032 section 7.1.
+const rfc8032Key = "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+
+func TestEd25519Formats(t *testing.T) {
+	t.Parallel()
+
+	key := decodeHex(t, rfc8032Key)
+	// aliceKey is the public key of the well-known Substrate development account Alice.
+	aliceKey := decodeHex(t, "d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d")
+
+	testCases := []struct {
+		name   string
+		encode func() (string, error)
+		want   string
+	}{
+		{
+			name:   "solana",
+			encode: func() (string, error) { return address.Solana(key) },
+			want:   "FVen3X669xLzsi6N2V91DoiyzHzg1uAgqiT8jZ9nS96Z",
+		},
+		{
+			name:   "solana system program",
+			encode: func() (string, error) { return address.Solana(make([]byte, 32)) },
+			want:   "11111111111111111111111111111111",
+		},
+		{
+			name:   "sui",
+			encode: func() (string, error) { return address.Sui(key) },
+			want:   "0x304af458e90e97c841685b8cbbc59b909f3e2cf150df590ada4c81452c29737d",
+		},
+		{
+			name:   "aptos",
+			encode: func() (string, error) { return address.Aptos(key) },
+			want:   "0x63c5215e87770d17b9f4cd47c777e322f4eb152cfd2054c1080fd9d57c48913b",
+		},
+		{
+			name:   "polkadot",
+			encode: func() (string, error) { return address.SS58(key, address.PolkadotSS58Prefix) },
+			want:   "15sND1xy2556eoAx6eGV6zkURiPJ9T9qJ8XMDHsYTuZezp7f",
+		},
+		{
+			name:   "polkadot alice",
+			encode: func() (string, error) { return address.SS58(aliceKey, address.PolkadotSS58Prefix) },
+			want:   "15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5",
+		},
+		{
+			name:   "substrate alice",
+			encode: func() (string, error) { return address.SS58(aliceKey, address.SubstrateSS58Prefix) },
+			want:   "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY",
+		},
+		{
+			name:   "ton bounceable",
+			encode: func() (string, error) { return address.TON(key, true) },
+			want:   "EQDNrJfJFisuFBrURjgosqcO_fh2K5foNWPzUr7PkC6Ipopv",
+		},
+		{
+			name:   "ton non-bounceable",
+			encode: func() (string, error) { return address.TON(key, false) },
+			want:   "UQDNrJfJFisuFBrURjgosqcO_fh2K5foNWPzUr7PkC6Ipteq",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tc.encode()
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestInvalidEd25519PublicKey(t *testing.T) {
+	t.Parallel()
+
+	for _, chain := range []schnorr.Chain{schnorr.ChainSolana, schnorr.ChainSui, schnorr.ChainAptos, schnorr.ChainPolkadot, schnorr.ChainTON} {
+		_, err := address.FromEd25519PublicKey(chain, decodeHex(t, rfc8032Key)[:31])
+		assert.ErrorIs(t, err, address.ErrPublicKey)
+	}
+
+	_, err := address.FromEd25519PublicKey("ADA", decodeHex(t, rfc8032Key))
+	assert.ErrorIs(t, err, address.ErrChain)
+
+	_, err = address.SS58(decodeHex(t, rfc8032Key), 16384)
+	assert.Error(t, err)
+}
+
+func TestFromSchnorrKeyshare(t *testing.T) {
+	t.Parallel()
+
+	shares, err := schnorrHelper.RunSchnorrKeygen(2, 3)
+	assert.NoError(t, err)
+
+	publicKey, err := schnorr.SchnorrKeysharePublicKey(shares[0])
+	assert.NoError(t, err)
+
+	path, err := schnorr.ParseDerivationPath("m/0/1")
+	assert.NoError(t, err)
+
+	childKey, err := schnorr.SchnorrKeyshareDeriveChildPublicKey(shares[0], path)
+	assert.NoError(t, err)
+
+	for _, chain := range []schnorr.Chain{schnorr.ChainSolana, schnorr.ChainSui, schnorr.ChainAptos, schnorr.ChainPolkadot, schnorr.ChainTON} {
+		want, err := address.FromEd25519PublicKey(chain, publicKey)
+		assert.NoError(t, err)
+
+		wantChild, err := address.FromEd25519PublicKey(chain, childKey)
+		assert.NoError(t, err)
+		assert.NotEqual(t, want, wantChild)
+
+		// All parties render the same addresses.
+		for _, share := range shares {
+			got, err := address.FromSchnorrKeyshare(share, chain, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+
+			got, err = address.FromSchnorrKeyshare(share, chain, path)
+			assert.NoError(t, err)
+			assert.Equal(t, wantChild, got)
+		}
+	}
+}
+
+// TestFromSchnorrKeyshareVaultAddress checks the Solana address of a vault
+// against the address any Solana wallet shows for the same key: Vultisig signs
+// with the root key, at the derivation path "m".
+func TestFromSchnorrKeyshareVaultAddress(t *testing.T) {
+	t.Parallel()
+
+	// the secret scalar of the seed of test 1 of RFC 8032 section 7.1
+	digest := sha512.Sum512(decodeHex(t, "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"))
+
+	secret, err := edwards25519.NewScalar().SetBytesWithClamping(digest[:32])
+	assert.NoError(t, err)
+
+	ids := []string{"p1", "p2", "p3"}
+
+	initiator, setup, err := schnorr.SchnorrKeyImportInitiatorNew(secret.Bytes(), nil, 2, ids)
+	assert.NoError(t, err)
+
+	parties := []schnorrHelper.Participant{{Session: initiator, ID: ids[0]}}
+
+	for _, id := range ids[1:] {
+		importer, err := schnorr.SchnorrKeyImporterNew(setup, id)
+		assert.NoError(t, err)
+
+		parties = append(parties, schnorrHelper.Participant{Session: importer, ID: id})
+	}
+
+	shares, err := schnorrHelper.RunSchnorrKeygenLoop(parties)
+	assert.NoError(t, err)
+
+	path, err := schnorr.VultisigDerivationPath(schnorr.ChainSolana, 0)
+	assert.NoError(t, err)
+
+	for _, share := range shares {
+		got, err := address.FromSchnorrKeyshare(share, schnorr.ChainSolana, path)
+		assert.NoError(t, err)
+		assert.Equal(t, "FVen3X669xLzsi6N2V91DoiyzHzg1uAgqiT8jZ9nS96Z", got)
+	}
+}
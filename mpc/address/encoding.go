@@ -0,0 +1,171 @@
+package address
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Check encodes a payload with a version byte and a double SHA-256 checksum.
+func base58Check(version byte, payload []byte) string {
+	data := append([]byte{version}, payload...)
+	first := sha256.Sum256(data)
+	checksum := sha256.Sum256(first[:])
+
+	return base58Encode(append(data, checksum[:4]...))
+}
+
+// base58Encode encodes data in the Bitcoin base58 alphabet.
+func base58Encode(data []byte) string {
+	var sb strings.Builder
+
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+
+		sb.WriteByte(base58Alphabet[0])
+	}
+
+	var digits []byte
+
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		digits = append(digits, base58Alphabet[mod.Int64()])
+	}
+
+	for i := len(digits) - 1; i >= 0; i-- {
+		sb.WriteByte(digits[i])
+	}
+
+	return sb.String()
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+const (
+	// bech32Const is the checksum constant of BIP-173 bech32.
+	bech32Const = 1
+	// bech32mConst is the checksum constant of BIP-350 bech32m.
+	bech32mConst = 0x2bc830a3
+)
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	expanded := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+
+	return expanded
+}
+
+// convertBits regroups 8-bit bytes into 5-bit groups, padding the last group.
+func convertBits(data []byte) []byte {
+	var (
+		acc  uint32
+		bits uint
+		out  = make([]byte, 0, (len(data)*8+4)/5)
+	)
+
+	for _, b := range data {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+
+		for bits >= 5 {
+			bits -= 5
+			out = append(out, byte(acc>>bits)&31)
+		}
+	}
+
+	if bits > 0 {
+		out = append(out, byte(acc<<(5-bits))&31)
+	}
+
+	return out
+}
+
+// bech32Encode encodes 5-bit groups with a human-readable part and the
+// checksum of the given constant.
+func bech32Encode(hrp string, data []byte, checksumConst uint32) string {
+	values := append(bech32HRPExpand(hrp), data...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ checksumConst
+
+	var sb strings.Builder
+
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+
+	for _, d := range data {
+		sb.WriteByte(bech32Charset[d])
+	}
+
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[(polymod>>(5*(5-i)))&31])
+	}
+
+	return sb.String()
+}
+
+// segwitAddress encodes a witness program of a version: bech32 for version 0
+// and bech32m for later versions.
+func segwitAddress(hrp string, version byte, program []byte) (string, error) {
+	if len(program) < 2 || len(program) > 40 {
+		return "", fmt.Errorf("address: invalid witness program length %d", len(program))
+	}
+
+	checksumConst := uint32(bech32Const)
+	if version > 0 {
+		checksumConst = bech32mConst
+	}
+
+	return bech32Encode(hrp, append([]byte{version}, convertBits(program)...), checksumConst), nil
+}
+
+// crc16 returns the CRC-16/XMODEM checksum of data.
+func crc16(data []byte) uint16 {
+	var crc uint16
+
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
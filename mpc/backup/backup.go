@@ -0,0 +1,504 @@
+// Package backup reads and writes Vultisig .vult backup files.
+//
+// A .vult file is the base64 encoding of a VaultContainer protobuf message.
+// The container holds a Vault protobuf message, base64 encoded and optionally
+// encrypted with AES-256-GCM under the SHA-256 hash of a password. The Vault
+// message carries the vault name, the local party ID, the signers, both
+// public keys, the hex chain code and both keyshares.
+//
+// The messages are encoded with protowire directly, so no generated code is needed.
+//
+// Key functionalities include:
+// - Encoding and decoding .vult files, with optional password encryption
+// - Restoring the ECDSA and EdDSA keyshares as mpc.Keyshare values
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/vultisig/go-wrappers/mpc"
+)
+
+// ContainerVersion is the version written to new containers.
+const ContainerVersion = 1
+
+// LibType identifies the MPC library which produced the keyshares.
+type LibType int32
+
+const (
+	LibTypeGG20 LibType = 0
+	LibTypeDKLS LibType = 1
+)
+
+var (
+	// ErrPasswordRequired is returned when an encrypted backup is decoded without a password.
+	ErrPasswordRequired = errors.New("backup: backup is encrypted, password required")
+	// ErrPassword is returned when an encrypted backup can not be decrypted.
+	ErrPassword = errors.New("backup: wrong password or corrupted backup")
+	// ErrMissingKeyshare is returned when a backup lacks the ECDSA or the EdDSA
+	// keyshare; a DKLS vault always has both.
+	ErrMissingKeyshare = errors.New("backup: missing keyshare")
+)
+
+// Backup is the content of a .vult file.
+type Backup struct {
+	Name          string
+	LocalPartyID  string
+	Signers       []string
+	CreatedAt     time.Time
+	ResharePrefix string
+	LibType       LibType
+
+	// HexChainCode is the hex encoded root chain code of the ECDSA key.
+	HexChainCode string
+	// PublicKeyECDSA is the hex encoded compressed secp256k1 public key.
+	PublicKeyECDSA string
+	// PublicKeyEdDSA is the hex encoded Ed25519 public key.
+	PublicKeyEdDSA string
+
+	// ECDSA is the DKLS keyshare; use mpc.ECDSAKeyshare for the go-dkls handle.
+	ECDSA mpc.Keyshare
+	// EdDSA is the Schnorr keyshare; use mpc.EdDSAKeyshare for the go-schnorr handle.
+	EdDSA mpc.Keyshare
+}
+
+// New creates a backup of a DKLS vault from its two keyshares. The public keys
+// and the chain code are read from the keyshares.
+//
+// Parameters:
+//   - name: string - the name of the vault.
+//   - localPartyID: string - the ID of the party owning the keyshares.
+//   - signers: []string - the IDs of all parties of the vault.
+//   - ecdsa: mpc.Keyshare - the ECDSA keyshare.
+//   - eddsa: mpc.Keyshare - the EdDSA keyshare.
+//
+// Returns:
+//   - *Backup: the backup.
+//   - error: an error if the keyshares can not be read.
+func New(name string, localPartyID string, signers []string, ecdsa mpc.Keyshare, eddsa mpc.Keyshare) (*Backup, error) {
+	if ecdsa.Scheme() != mpc.KindECDSA || eddsa.Scheme() != mpc.KindEdDSA {
+		return nil, mpc.ErrSchemeMismatch
+	}
+
+	ecdsaPublicKey, err := ecdsa.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	eddsaPublicKey, err := eddsa.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	chainCode, err := ecdsa.ChainCode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Backup{
+		Name:           name,
+		LocalPartyID:   localPartyID,
+		Signers:        signers,
+		CreatedAt:      time.Now().UTC(),
+		LibType:        LibTypeDKLS,
+		HexChainCode:   hex.EncodeToString(chainCode),
+		PublicKeyECDSA: hex.EncodeToString(ecdsaPublicKey),
+		PublicKeyEdDSA: hex.EncodeToString(eddsaPublicKey),
+		ECDSA:          ecdsa,
+		EdDSA:          eddsa,
+	}, nil
+}
+
+// Vault message field numbers.
+const (
+	vaultName           protowire.Number = 1
+	vaultPublicKeyECDSA protowire.Number = 2
+	vaultPublicKeyEdDSA protowire.Number = 3
+	vaultSigners        protowire.Number = 4
+	vaultCreatedAt      protowire.Number = 5
+	vaultHexChainCode   protowire.Number = 6
+	vaultKeyShares      protowire.Number = 7
+	vaultLocalPartyID   protowire.Number = 8
+	vaultResharePrefix  protowire.Number = 9
+	vaultLibType        protowire.Number = 10
+
+	keySharePublicKey protowire.Number = 1
+	keyShareKeyshare  protowire.Number = 2
+
+	timestampSeconds protowire.Number = 1
+	timestampNanos   protowire.Number = 2
+
+	containerVersion     protowire.Number = 1
+	containerVault       protowire.Number = 2
+	containerIsEncrypted protowire.Number = 3
+)
+
+// Encode encodes the backup as the content of a .vult file.
+//
+// Parameters:
+//   - password: string - the password, or an empty string for an unencrypted backup.
+//
+// Returns:
+//   - []byte: the content of the .vult file.
+//   - error: ErrMissingKeyshare if a keyshare is nil, or an error if a keyshare
+//     can not be serialized or the encryption fails.
+func (b *Backup) Encode(password string) ([]byte, error) {
+	vault, err := b.marshalVault()
+	if err != nil {
+		return nil, err
+	}
+
+	if password != "" {
+		if vault, err = encrypt(vault, password); err != nil {
+			return nil, err
+		}
+	}
+
+	var container []byte
+	container = protowire.AppendTag(container, containerVersion, protowire.VarintType)
+	container = protowire.AppendVarint(container, ContainerVersion)
+	container = appendString(container, containerVault, base64.StdEncoding.EncodeToString(vault))
+
+	if password != "" {
+		container = protowire.AppendTag(container, containerIsEncrypted, protowire.VarintType)
+		container = protowire.AppendVarint(container, 1)
+	}
+
+	return []byte(base64.StdEncoding.EncodeToString(container)), nil
+}
+
+func (b *Backup) marshalVault() ([]byte, error) {
+	var buf []byte
+	buf = appendString(buf, vaultName, b.Name)
+	buf = appendString(buf, vaultPublicKeyECDSA, b.PublicKeyECDSA)
+	buf = appendString(buf, vaultPublicKeyEdDSA, b.PublicKeyEdDSA)
+
+	for _, signer := range b.Signers {
+		buf = appendString(buf, vaultSigners, signer)
+	}
+
+	if !b.CreatedAt.IsZero() {
+		var ts []byte
+		ts = protowire.AppendTag(ts, timestampSeconds, protowire.VarintType)
+		ts = protowire.AppendVarint(ts, uint64(b.CreatedAt.Unix()))
+		ts = protowire.AppendTag(ts, timestampNanos, protowire.VarintType)
+		ts = protowire.AppendVarint(ts, uint64(b.CreatedAt.Nanosecond()))
+
+		buf = protowire.AppendTag(buf, vaultCreatedAt, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, ts)
+	}
+
+	buf = appendString(buf, vaultHexChainCode, b.HexChainCode)
+
+	for _, share := range []struct {
+		publicKey string
+		keyshare  mpc.Keyshare
+	}{{b.PublicKeyECDSA, b.ECDSA}, {b.PublicKeyEdDSA, b.EdDSA}} {
+		if share.keyshare == nil {
+			return nil, ErrMissingKeyshare
+		}
+
+		data, err := share.keyshare.ToBytes()
+		if err != nil {
+			return nil, err
+		}
+
+		var ks []byte
+		ks = appendString(ks, keySharePublicKey, share.publicKey)
+		ks = appendString(ks, keyShareKeyshare, base64.StdEncoding.EncodeToString(data))
+
+		buf = protowire.AppendTag(buf, vaultKeyShares, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, ks)
+	}
+
+	buf = appendString(buf, vaultLocalPartyID, b.LocalPartyID)
+	buf = appendString(buf, vaultResharePrefix, b.ResharePrefix)
+
+	if b.LibType != 0 {
+		buf = protowire.AppendTag(buf, vaultLibType, protowire.VarintType)
+		buf = protowire.AppendVarint(buf, uint64(b.LibType))
+	}
+
+	return buf, nil
+}
+
+// appendString appends a string field, omitting empty strings like proto3 does.
+func appendString(buf []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return buf
+	}
+
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+
+	return protowire.AppendString(buf, s)
+}
+
+// IsEncrypted reports whether the content of a .vult file is password encrypted.
+func IsEncrypted(data []byte) (bool, error) {
+	_, encrypted, err := unmarshalContainer(data)
+
+	return encrypted, err
+}
+
+// Decode decodes the content of a .vult file and restores its keyshares.
+//
+// Parameters:
+//   - data: []byte - the content of the .vult file.
+//   - password: string - the password of an encrypted backup; ignored otherwise.
+//
+// Returns:
+//   - *Backup: the backup; the caller must Close its keyshares.
+//   - error: ErrPasswordRequired or ErrPassword for encrypted backups,
+//     ErrMissingKeyshare if a keyshare is absent or empty, or a decoding error.
+func Decode(data []byte, password string) (*Backup, error) {
+	vault, encrypted, err := unmarshalContainer(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if encrypted {
+		if password == "" {
+			return nil, ErrPasswordRequired
+		}
+
+		if vault, err = decrypt(vault, password); err != nil {
+			return nil, err
+		}
+	}
+
+	return unmarshalVault(vault)
+}
+
+func unmarshalContainer(data []byte) ([]byte, bool, error) {
+	container, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, false, fmt.Errorf("backup: container is not base64: %w", err)
+	}
+
+	var (
+		vault     []byte
+		encrypted bool
+	)
+
+	err = walk(container, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch {
+		case num == containerVault && typ == protowire.BytesType:
+			decoded, err := base64.StdEncoding.DecodeString(string(value))
+			if err != nil {
+				return fmt.Errorf("backup: vault is not base64: %w", err)
+			}
+
+			vault = decoded
+		case num == containerIsEncrypted && typ == protowire.VarintType:
+			encrypted = varint != 0
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if vault == nil {
+		return nil, false, errors.New("backup: container holds no vault")
+	}
+
+	return vault, encrypted, nil
+}
+
+func unmarshalVault(buf []byte) (*Backup, error) {
+	b := &Backup{}
+	shares := map[string]string{}
+
+	err := walk(buf, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch num {
+		case vaultName:
+			b.Name = string(value)
+		case vaultPublicKeyECDSA:
+			b.PublicKeyECDSA = string(value)
+		case vaultPublicKeyEdDSA:
+			b.PublicKeyEdDSA = string(value)
+		case vaultSigners:
+			b.Signers = append(b.Signers, string(value))
+		case vaultHexChainCode:
+			b.HexChainCode = string(value)
+		case vaultLocalPartyID:
+			b.LocalPartyID = string(value)
+		case vaultResharePrefix:
+			b.ResharePrefix = string(value)
+		case vaultLibType:
+			b.LibType = LibType(varint)
+		case vaultCreatedAt:
+			var seconds, nanos uint64
+
+			err := walk(value, func(num protowire.Number, _ protowire.Type, _ []byte, varint uint64) error {
+				switch num {
+				case timestampSeconds:
+					seconds = varint
+				case timestampNanos:
+					nanos = varint
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			b.CreatedAt = time.Unix(int64(seconds), int64(nanos)).UTC()
+		case vaultKeyShares:
+			var publicKey, keyshare string
+
+			err := walk(value, func(num protowire.Number, _ protowire.Type, value []byte, _ uint64) error {
+				switch num {
+				case keySharePublicKey:
+					publicKey = string(value)
+				case keyShareKeyshare:
+					keyshare = string(value)
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			shares[publicKey] = keyshare
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if b.LibType != LibTypeDKLS {
+		return nil, fmt.Errorf("backup: unsupported library type %d", b.LibType)
+	}
+
+	if b.ECDSA, err = restore(mpc.ECDSA, shares, b.PublicKeyECDSA); err != nil {
+		return nil, err
+	}
+
+	if b.EdDSA, err = restore(mpc.EdDSA, shares, b.PublicKeyEdDSA); err != nil {
+		b.ECDSA.Close()
+
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// restore deserializes the keyshare of a public key.
+func restore(scheme mpc.Scheme, shares map[string]string, publicKey string) (mpc.Keyshare, error) {
+	encoded := shares[publicKey]
+	if publicKey == "" || encoded == "" {
+		return nil, fmt.Errorf("%w: no %s keyshare", ErrMissingKeyshare, scheme.Kind())
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("backup: %s keyshare is not base64: %w", scheme.Kind(), err)
+	}
+
+	share, err := scheme.KeyshareFromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("backup: %s keyshare: %w", scheme.Kind(), err)
+	}
+
+	return share, nil
+}
+
+// walk calls fn for every field of a protobuf message. value is set for
+// length-delimited fields and varint for varint fields.
+func walk(buf []byte, fn func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error) error {
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return fmt.Errorf("backup: malformed message: %w", protowire.ParseError(n))
+		}
+
+		buf = buf[n:]
+
+		var (
+			value  []byte
+			varint uint64
+		)
+
+		switch typ {
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(buf)
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(buf)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, buf)
+		}
+
+		if n < 0 {
+			return fmt.Errorf("backup: malformed message: %w", protowire.ParseError(n))
+		}
+
+		buf = buf[n:]
+
+		if err := fn(num, typ, value, varint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newAEAD(password string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(password))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encrypt seals the vault as nonce || ciphertext.
+func encrypt(vault []byte, password string) ([]byte, error) {
+	aead, err := newAEAD(password)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, vault, nil), nil
+}
+
+func decrypt(sealed []byte, password string) ([]byte, error) {
+	aead, err := newAEAD(password)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrPassword
+	}
+
+	vault, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrPassword
+	}
+
+	return vault, nil
+}
@@ -0,0 +1,175 @@
+package backup_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vultisig/go-wrappers/mpc"
+	"github.com/vultisig/go-wrappers/mpc/backup"
+	"github.com/vultisig/go-wrappers/mpc/router"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var signers = []string{"iphone-1234", "mac-5678"}
+
+func keygen(t *testing.T, scheme mpc.Scheme) mpc.Keyshare {
+	setup, err := scheme.KeygenSetupMsgNew(2, nil, signers)
+	assert.NoError(t, err)
+
+	sessions := make(map[string]mpc.Finisher[mpc.Keyshare], len(signers))
+	for _, id := range signers {
+		sessions[id], err = scheme.KeygenSessionFromSetup(setup, id)
+		assert.NoError(t, err)
+	}
+
+	shares, err := router.Run(context.Background(), sessions, time.Minute)
+	assert.NoError(t, err)
+
+	return shares[signers[0]]
+}
+
+func TestBackup(t *testing.T) {
+	t.Parallel()
+
+	ecdsa := keygen(t, mpc.ECDSA)
+	eddsa := keygen(t, mpc.EdDSA)
+
+	b, err := backup.New("Main Vault", signers[0], signers, ecdsa, eddsa)
+	assert.NoError(t, err)
+
+	_, err = backup.New("Main Vault", signers[0], signers, eddsa, ecdsa)
+	assert.ErrorIs(t, err, mpc.ErrSchemeMismatch)
+
+	testCases := []struct {
+		name     string
+		password string
+	}{
+		{name: "plain", password: ""},
+		{name: "encrypted", password: "hunter2"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := b.Encode(tc.password)
+			assert.NoError(t, err)
+
+			encrypted, err := backup.IsEncrypted(data)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.password != "", encrypted)
+
+			if encrypted {
+				_, err = backup.Decode(data, "")
+				assert.ErrorIs(t, err, backup.ErrPasswordRequired)
+
+				_, err = backup.Decode(data, "wrong")
+				assert.ErrorIs(t, err, backup.ErrPassword)
+			}
+
+			restored, err := backup.Decode(data, tc.password)
+			assert.NoError(t, err)
+
+			assert.Equal(t, b.Name, restored.Name)
+			assert.Equal(t, b.LocalPartyID, restored.LocalPartyID)
+			assert.Equal(t, b.Signers, restored.Signers)
+			assert.Equal(t, b.HexChainCode, restored.HexChainCode)
+			assert.Equal(t, b.PublicKeyECDSA, restored.PublicKeyECDSA)
+			assert.Equal(t, b.PublicKeyEdDSA, restored.PublicKeyEdDSA)
+			assert.Equal(t, backup.LibTypeDKLS, restored.LibType)
+			assert.True(t, b.CreatedAt.Equal(restored.CreatedAt))
+
+			for _, pair := range [][2]mpc.Keyshare{{ecdsa, restored.ECDSA}, {eddsa, restored.EdDSA}} {
+				want, err := pair[0].PublicKey()
+				assert.NoError(t, err)
+
+				got, err := pair[1].PublicKey()
+				assert.NoError(t, err)
+				assert.Equal(t, want, got)
+			}
+
+			handle, err := mpc.ECDSAKeyshare(restored.ECDSA)
+			assert.NoError(t, err)
+			assert.NotZero(t, handle.Handle())
+
+			_, err = mpc.EdDSAKeyshare(restored.EdDSA)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// emptyKeyshare serializes to nothing.
+type emptyKeyshare struct {
+	mpc.Keyshare
+}
+
+func (emptyKeyshare) ToBytes() ([]byte, error) {
+	return nil, nil
+}
+
+func TestMissingKeyshare(t *testing.T) {
+	t.Parallel()
+
+	ecdsa := keygen(t, mpc.ECDSA)
+	eddsa := keygen(t, mpc.EdDSA)
+
+	testCases := []struct {
+		name   string
+		modify func(b *backup.Backup)
+	}{
+		{name: "no public key", modify: func(b *backup.Backup) { b.PublicKeyEdDSA = "" }},
+		{name: "empty keyshare", modify: func(b *backup.Backup) { b.EdDSA = emptyKeyshare{b.EdDSA} }},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := backup.New("Main Vault", signers[0], signers, ecdsa, eddsa)
+			assert.NoError(t, err)
+
+			tc.modify(b)
+
+			data, err := b.Encode("")
+			assert.NoError(t, err)
+
+			_, err = backup.Decode(data, "")
+			assert.ErrorIs(t, err, backup.ErrMissingKeyshare)
+		})
+	}
+
+	b, err := backup.New("Main Vault", signers[0], signers, ecdsa, eddsa)
+	assert.NoError(t, err)
+
+	b.EdDSA = nil
+
+	_, err = b.Encode("")
+	assert.ErrorIs(t, err, backup.ErrMissingKeyshare)
+}
+
+func TestMalformed(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{name: "not base64", data: "not base64!"},
+		{name: "truncated message", data: "Eg=="},
+		{name: "no vault", data: "CAE="},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := backup.Decode([]byte(tc.data), "")
+			assert.Error(t, err)
+		})
+	}
+}
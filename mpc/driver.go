@@ -0,0 +1,169 @@
+package mpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Transport moves protocol messages between the local party and its peers.
+type Transport interface {
+	// Send delivers a message to the party with the given ID.
+	Send(ctx context.Context, receiver string, message []byte) error
+	// Receive blocks until the next message for the local party arrives
+	// and returns it together with the ID of its sender.
+	Receive(ctx context.Context) (sender string, message []byte, err error)
+}
+
+// Driver runs a session of the local party to completion over a Transport.
+type Driver struct {
+	// ID is the ID of the local party.
+	ID string
+	// Parties lists the IDs of all parties of the session, including ID.
+	Parties []string
+	// Transport moves the messages.
+	Transport Transport
+	// Timeout bounds the whole run. Zero means the run is bounded by the context only.
+	Timeout time.Duration
+}
+
+// IncompleteError is returned when a session is cancelled or times out before it finishes.
+type IncompleteError struct {
+	// Missing lists the peers which have not delivered a single message.
+	Missing []string
+	// Err is the cause, usually context.Canceled or context.DeadlineExceeded.
+	Err error
+}
+
+func (e *IncompleteError) Error() string {
+	if len(e.Missing) == 0 {
+		return fmt.Sprintf("mpc: session did not finish: %v", e.Err)
+	}
+
+	return fmt.Sprintf("mpc: session did not finish: %v; no messages from %s", e.Err, strings.Join(e.Missing, ", "))
+}
+
+func (e *IncompleteError) Unwrap() error {
+	return e.Err
+}
+
+// Run pumps messages between the session and the transport until the session
+// reports that it has finished. It neither finishes nor closes the session.
+//
+// Parameters:
+//   - ctx: context.Context - cancels the run.
+//   - session: Session - the session of the local party.
+//
+// Returns:
+//   - error: an *IncompleteError if the context is done first, or the error of the
+//     session or the transport.
+func (d *Driver) Run(ctx context.Context, session Session) error {
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+
+	delivered := make(map[string]bool, len(d.Parties))
+
+	for {
+		if err := d.flush(ctx, session); err != nil {
+			return d.wrap(ctx, delivered, err)
+		}
+
+		sender, msg, err := d.Transport.Receive(ctx)
+		if err != nil {
+			return d.wrap(ctx, delivered, err)
+		}
+
+		delivered[sender] = true
+
+		finished, err := session.InputMessage(msg)
+		if err != nil {
+			return fmt.Errorf("mpc: input message from %s: %w", sender, err)
+		}
+
+		if finished {
+			// Messages of the last round may still be needed by the peers.
+			if err := d.flush(ctx, session); err != nil {
+				return d.wrap(ctx, delivered, err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// flush sends all pending output messages of the session.
+func (d *Driver) flush(ctx context.Context, session Session) error {
+	for {
+		msg, err := session.OutputMessage()
+		if err != nil {
+			return err
+		}
+
+		if len(msg) == 0 {
+			return nil
+		}
+
+		for idx := 0; idx < len(d.Parties); idx++ {
+			receiver, err := session.MessageReceiver(msg, idx)
+			if err != nil {
+				return err
+			}
+
+			if receiver == "" {
+				break
+			}
+
+			if err := d.Transport.Send(ctx, receiver, msg); err != nil {
+				return fmt.Errorf("mpc: send message to %s: %w", receiver, err)
+			}
+		}
+	}
+}
+
+func (d *Driver) wrap(ctx context.Context, delivered map[string]bool, err error) error {
+	if ctx.Err() == nil {
+		return err
+	}
+
+	missing := []string{}
+	for _, id := range d.Parties {
+		if id != d.ID && !delivered[id] {
+			missing = append(missing, id)
+		}
+	}
+
+	return &IncompleteError{Missing: missing, Err: ctx.Err()}
+}
+
+// Finisher is a session which produces a result of type T.
+// KeygenSession and SignSession are Finishers.
+type Finisher[T any] interface {
+	Session
+	Finish() (T, error)
+}
+
+// Drive runs the session to completion, finishes it and always closes it.
+//
+// Parameters:
+//   - ctx: context.Context - cancels the run.
+//   - d: *Driver - the driver of the local party.
+//   - session: Finisher[T] - a keygen, refresh, migration, QC, import, sign or pre-sign session.
+//
+// Returns:
+//   - T: the keyshare or signature produced by the session.
+//   - error: an *IncompleteError if the context is done first, or any other error of the run.
+func Drive[T any](ctx context.Context, d *Driver, session Finisher[T]) (T, error) {
+	defer session.Close()
+
+	var zero T
+
+	if err := d.Run(ctx, session); err != nil {
+		return zero, err
+	}
+
+	return session.Finish()
+}
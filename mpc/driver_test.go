@@ -0,0 +1,153 @@
+package mpc_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vultisig/go-wrappers/mpc"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type envelope struct {
+	sender  string
+	message []byte
+}
+
+// chanTransport delivers messages through buffered channels, one per party.
+type chanTransport struct {
+	id    string
+	inbox map[string]chan envelope
+}
+
+func newChanTransports(ids []string) map[string]*chanTransport {
+	inbox := make(map[string]chan envelope, len(ids))
+	for _, id := range ids {
+		inbox[id] = make(chan envelope, 1024)
+	}
+
+	transports := make(map[string]*chanTransport, len(ids))
+	for _, id := range ids {
+		transports[id] = &chanTransport{id: id, inbox: inbox}
+	}
+
+	return transports
+}
+
+func (c *chanTransport) Send(ctx context.Context, receiver string, message []byte) error {
+	select {
+	case c.inbox[receiver] <- envelope{sender: c.id, message: message}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *chanTransport) Receive(ctx context.Context) (string, []byte, error) {
+	select {
+	case e := <-c.inbox[c.id]:
+		return e.sender, e.message, nil
+	case <-ctx.Done():
+		return "", nil, ctx.Err()
+	}
+}
+
+func driveKeygen(t *testing.T, scheme mpc.Scheme, threshold int, ids []string, running []string, timeout time.Duration) ([]mpc.Keyshare, []error) {
+	setup, err := scheme.KeygenSetupMsgNew(threshold, nil, ids)
+	assert.NoError(t, err)
+
+	transports := newChanTransports(ids)
+	shares := make([]mpc.Keyshare, len(running))
+	errs := make([]error, len(running))
+
+	var wg sync.WaitGroup
+	for i, id := range running {
+		wg.Add(1)
+
+		go func(i int, id string) {
+			defer wg.Done()
+
+			session, err := scheme.KeygenSessionFromSetup(setup, id)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			d := &mpc.Driver{ID: id, Parties: ids, Transport: transports[id], Timeout: timeout}
+			shares[i], errs[i] = mpc.Drive[mpc.Keyshare](context.Background(), d, session)
+		}(i, id)
+	}
+
+	wg.Wait()
+
+	return shares, errs
+}
+
+func TestDriveKeygenAndSign(t *testing.T) {
+	t.Parallel()
+
+	ids := []string{"p1", "p2", "p3"}
+
+	shares, errs := driveKeygen(t, mpc.EdDSA, 2, ids, ids, time.Minute)
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+
+	publicKey, err := shares[0].PublicKey()
+	assert.NoError(t, err)
+
+	keyID, err := shares[0].KeyID()
+	assert.NoError(t, err)
+
+	msg := []byte("drive sign")
+	signers := []string{"p1", "p3"}
+
+	setup, err := mpc.EdDSA.SignSetupMsgNew(keyID, nil, msg, signers)
+	assert.NoError(t, err)
+
+	transports := newChanTransports(signers)
+	signatures := make([][]byte, len(signers))
+
+	var wg sync.WaitGroup
+	for i, id := range signers {
+		wg.Add(1)
+
+		go func(i int, id string, share mpc.Keyshare) {
+			defer wg.Done()
+
+			session, err := mpc.EdDSA.SignSessionFromSetup(setup, id, share)
+			assert.NoError(t, err)
+
+			d := &mpc.Driver{ID: id, Parties: signers, Transport: transports[id]}
+			signatures[i], err = mpc.Drive[[]byte](context.Background(), d, session)
+			assert.NoError(t, err)
+		}(i, id, shares[2*i])
+	}
+
+	wg.Wait()
+
+	for _, signature := range signatures {
+		assert.True(t, ed25519.Verify(publicKey, msg, signature))
+	}
+}
+
+func TestDriveTimeout(t *testing.T) {
+	t.Parallel()
+
+	ids := []string{"p1", "p2", "p3"}
+
+	_, errs := driveKeygen(t, mpc.ECDSA, 2, ids, []string{"p1", "p2"}, 500*time.Millisecond)
+
+	for _, err := range errs {
+		var incomplete *mpc.IncompleteError
+
+		assert.True(t, errors.As(err, &incomplete))
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, []string{"p3"}, incomplete.Missing)
+		assert.Contains(t, err.Error(), "no messages from p3")
+	}
+}
@@ -0,0 +1,337 @@
+package mpc
+
+import (
+	"fmt"
+
+	dkls "github.com/vultisig/go-wrappers/go-dkls/sessions"
+)
+
+// ECDSA is the DKLS23 ECDSA scheme over secp256k1 backed by go-dkls.
+var ECDSA Scheme = ecdsaScheme{}
+
+type ecdsaScheme struct{}
+
+type ecdsaKeyshare struct {
+	*dkls.Keyshare
+	names []string
+}
+
+// NewECDSAKeyshare wraps a go-dkls keyshare. The returned Keyshare takes ownership of it.
+func NewECDSAKeyshare(share *dkls.Keyshare) Keyshare {
+	return ecdsaKeyshare{Keyshare: share}
+}
+
+// ECDSAKeyshare returns the go-dkls keyshare underlying an ECDSA Keyshare.
+//
+// Returns ErrSchemeMismatch if the keyshare is not an ECDSA keyshare.
+func ECDSAKeyshare(share Keyshare) (*dkls.Keyshare, error) {
+	s, ok := share.(ecdsaKeyshare)
+	if !ok {
+		return nil, ErrSchemeMismatch
+	}
+
+	return s.Keyshare, nil
+}
+
+func (ecdsaKeyshare) Scheme() Kind {
+	return KindECDSA
+}
+
+func (k ecdsaKeyshare) Info() (KeyshareInfo, error) {
+	info, err := k.Keyshare.Info()
+	if err != nil {
+		return KeyshareInfo{}, err
+	}
+
+	return KeyshareInfo{Threshold: info.Threshold, Parties: info.Parties, PartyIndex: info.PartyIndex, PartyNames: k.names}, nil
+}
+
+type ecdsaKeygenSession struct {
+	*dkls.KeygenSession
+	names []string
+}
+
+func (s ecdsaKeygenSession) Finish() (Keyshare, error) {
+	share, err := s.KeygenSession.Finish()
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdsaKeyshare{share, s.names}, nil
+}
+
+type ecdsaQcSession struct {
+	*dkls.QcSession
+	names []string
+}
+
+func (s ecdsaQcSession) Finish() (Keyshare, error) {
+	share, err := s.QcSession.Finish()
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdsaKeyshare{share, s.names}, nil
+}
+
+// ecdsaSignSession verifies the signature against the public key of the
+// signing key before returning it.
+type ecdsaSignSession struct {
+	*dkls.SignSession
+	publicKey   []byte
+	messageHash []byte
+}
+
+func (s ecdsaSignSession) Finish() ([]byte, error) {
+	signature, err := s.SignSession.Finish()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dkls.DklsVerifySignatureWithPublicKey(s.publicKey, s.messageHash, signature); err != nil {
+		return nil, err
+	}
+
+	return signature, nil
+}
+
+func (ecdsaScheme) Kind() Kind {
+	return KindECDSA
+}
+
+func (ecdsaScheme) KeygenSetupMsgNew(threshold int, keyID []byte, ids []string) ([]byte, error) {
+	return dkls.DklsKeygenSetupMsgNew(threshold, keyID, joinIDs(ids))
+}
+
+func (ecdsaScheme) KeygenSessionFromSetup(setup []byte, id string) (KeygenSession, error) {
+	names, err := ecdsaPartyNames(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := dkls.NewKeygenSession(setup, []byte(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdsaKeygenSession{s, names}, nil
+}
+
+func (ecdsaScheme) KeyRefreshSessionFromSetup(setup []byte, id string, oldKeyshare Keyshare) (KeygenSession, error) {
+	names, err := ecdsaPartyNames(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	share, err := ECDSAKeyshare(oldKeyshare)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := share.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ecdsaRefreshSupported(info, names); err != nil {
+		return nil, err
+	}
+
+	s, err := dkls.NewKeyRefreshSession(setup, []byte(id), share)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdsaKeygenSession{s, names}, nil
+}
+
+func (ecdsaScheme) KeyMigrateSessionFromSetup(setup []byte, id string, publicKey []byte, rootChainCode []byte, secretCoefficient []byte) (KeygenSession, error) {
+	names, err := ecdsaPartyNames(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := dkls.NewKeyMigrateSession(setup, []byte(id), publicKey, rootChainCode, secretCoefficient)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdsaKeygenSession{s, names}, nil
+}
+
+func (ecdsaScheme) KeyshareToRefreshBytes(keyshare Keyshare) ([]byte, error) {
+	share, err := ECDSAKeyshare(keyshare)
+	if err != nil {
+		return nil, err
+	}
+
+	return share.ToRefreshBytes()
+}
+
+func (ecdsaScheme) KeyRecoverySessionFromSetup(setup []byte, id string, refreshShare []byte) (KeygenSession, error) {
+	names, err := ecdsaPartyNames(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := dkls.DklsRefreshShareInfo(refreshShare)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ecdsaRefreshSupported(info, names); err != nil {
+		return nil, err
+	}
+
+	share, err := dkls.RefreshShareFromBytes(refreshShare)
+	if err != nil {
+		return nil, err
+	}
+	defer share.Close()
+
+	s, err := dkls.NewKeyRecoverySession(setup, []byte(id), share)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdsaKeygenSession{s, names}, nil
+}
+
+func (ecdsaScheme) QcSetupMsgNew(keyshare Keyshare, threshold int, ids []string, oldParties []int, newParties []int) ([]byte, error) {
+	share, err := ECDSAKeyshare(keyshare)
+	if err != nil {
+		return nil, err
+	}
+
+	return share.QcSetupMsgNew(threshold, ids, oldParties, newParties)
+}
+
+func (ecdsaScheme) QcSessionFromSetup(setup []byte, id string, keyshare Keyshare) (KeygenSession, error) {
+	names, err := ecdsaPartyNames(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	var share *dkls.Keyshare
+	if keyshare != nil {
+		var err error
+		if share, err = ECDSAKeyshare(keyshare); err != nil {
+			return nil, err
+		}
+	}
+
+	s, err := dkls.NewQcSession(setup, id, share)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdsaQcSession{s, names}, nil
+}
+
+func (ecdsaScheme) SignSetupMsgNew(keyID []byte, chainPath []byte, message []byte, ids []string) ([]byte, error) {
+	return dkls.DklsSignSetupMsgNew(keyID, chainPath, message, joinIDs(ids))
+}
+
+func (ecdsaScheme) SignSessionFromSetup(setup []byte, id string, keyshare Keyshare) (SignSession, error) {
+	share, err := ECDSAKeyshare(keyshare)
+	if err != nil {
+		return nil, err
+	}
+
+	chainPath, err := dkls.DklsDecodeChainPath(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	messageHash, err := dkls.DklsDecodeMessage(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := share.DeriveChildPublicKey(chainPath)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := dkls.NewSignSession(setup, []byte(id), share)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdsaSignSession{SignSession: s, publicKey: publicKey, messageHash: messageHash}, nil
+}
+
+func (ecdsaScheme) KeyExportReceiverNew(keyshare Keyshare, ids []string) (ExportReceiver, []byte, error) {
+	share, err := ECDSAKeyshare(keyshare)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dkls.NewKeyExportReceiver(share, ids)
+}
+
+func (ecdsaScheme) KeyExporter(keyshare Keyshare, id string, setup []byte) ([]byte, string, error) {
+	share, err := ECDSAKeyshare(keyshare)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return share.Export(id, setup)
+}
+
+func (ecdsaScheme) KeyImportInitiatorNew(privateKey []byte, rootChain []byte, threshold int, ids []string) (KeygenSession, []byte, error) {
+	s, setup, err := dkls.NewKeyImportInitiator(privateKey, rootChain, uint8(threshold), ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ecdsaKeygenSession{&s.KeygenSession, ids}, setup, nil
+}
+
+func (ecdsaScheme) KeyImporterNew(setup []byte, id string) (KeygenSession, error) {
+	names, err := ecdsaPartyNames(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := dkls.NewKeyImporter(setup, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdsaKeygenSession{&s.KeygenSession, names}, nil
+}
+
+func (ecdsaScheme) KeyshareFromBytes(buf []byte) (Keyshare, error) {
+	share, err := dkls.KeyshareFromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdsaKeyshare{Keyshare: share}, nil
+}
+
+// ecdsaPartyNames returns the names of the parties of the keyshares produced by
+// a keygen or QC setup message, by party index.
+func ecdsaPartyNames(setup []byte) ([]string, error) {
+	decoded, err := dkls.DklsDecodeSetup(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	return setupPartyNames(decoded.Parties, decoded.NewParties), nil
+}
+
+// ecdsaRefreshSupported checks that go-dkls can run a key refresh of the quorum
+// of info with the parties of a setup message. With fewer than all parties of
+// the quorum, go-dkls only refreshes when the parties are those holding the
+// lowest party indices; every party checks that its own index is among them, so
+// an unsupported subset fails up front rather than during the protocol.
+func ecdsaRefreshSupported(info dkls.KeyshareInfo, names []string) error {
+	if len(names) < info.Parties && info.PartyIndex >= len(names) {
+		return fmt.Errorf("%w: key refresh with %d of %d parties needs the parties of the lowest %d indices, not party index %d",
+			ErrUnsupported, len(names), info.Parties, len(names), info.PartyIndex)
+	}
+
+	return nil
+}
@@ -0,0 +1,529 @@
+package mpc
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"sync/atomic"
+
+	schnorr "github.com/vultisig/go-wrappers/go-schnorr/sessions"
+)
+
+// EdDSA is the FROST-style EdDSA scheme over Ed25519 backed by go-schnorr.
+var EdDSA Scheme = eddsaScheme{}
+
+type eddsaScheme struct{}
+
+// schnorrHandle holds a go-schnorr handle which is reset to zero once released.
+// Like the typed handles of go-dkls, a handle with a free function which is
+// garbage collected without being closed is logged and released from a finalizer.
+type schnorrHandle struct {
+	hnd  atomic.Int32
+	kind string
+	free func(schnorr.Handle) error
+}
+
+func newSchnorrHandle(kind string, hnd schnorr.Handle, free func(schnorr.Handle) error) *schnorrHandle {
+	h := &schnorrHandle{kind: kind, free: free}
+	h.hnd.Store(int32(hnd))
+
+	if free != nil {
+		runtime.SetFinalizer(h, (*schnorrHandle).finalize)
+	}
+
+	return h
+}
+
+func (h *schnorrHandle) get() schnorr.Handle {
+	return schnorr.Handle(h.hnd.Load())
+}
+
+// Close releases the handle with its free function. It is safe to call Close
+// more than once; only the first call reaches the native library.
+func (h *schnorrHandle) Close() error {
+	hnd := schnorr.Handle(h.hnd.Swap(0))
+	if hnd == 0 || h.free == nil {
+		return nil
+	}
+
+	return h.free(hnd)
+}
+
+func (h *schnorrHandle) finalize() {
+	hnd := h.get()
+	if hnd == 0 {
+		return
+	}
+
+	log.Printf("mpc: leaked go-schnorr %s handle %d, releasing it from finalizer", h.kind, hnd)
+
+	if err := h.Close(); err != nil {
+		log.Printf("mpc: failed to release leaked go-schnorr %s handle %d: %v", h.kind, hnd, err)
+	}
+}
+
+type eddsaKeyshare struct {
+	*schnorrHandle
+	names []string
+}
+
+// NewEdDSAKeyshare wraps a go-schnorr keyshare handle.
+func NewEdDSAKeyshare(share schnorr.Handle) Keyshare {
+	return eddsaKeyshare{schnorrHandle: newSchnorrHandle("keyshare", share, nil)}
+}
+
+// EdDSAKeyshare returns the go-schnorr keyshare handle underlying an EdDSA Keyshare.
+//
+// Returns ErrSchemeMismatch if the keyshare is not an EdDSA keyshare.
+func EdDSAKeyshare(share Keyshare) (schnorr.Handle, error) {
+	s, ok := share.(eddsaKeyshare)
+	if !ok {
+		return 0, ErrSchemeMismatch
+	}
+
+	return s.get(), nil
+}
+
+func (eddsaKeyshare) Scheme() Kind {
+	return KindEdDSA
+}
+
+func (k eddsaKeyshare) ToBytes() ([]byte, error) {
+	defer runtime.KeepAlive(k.schnorrHandle)
+
+	return schnorr.SchnorrKeyshareToBytes(k.get())
+}
+
+func (k eddsaKeyshare) PublicKey() ([]byte, error) {
+	defer runtime.KeepAlive(k.schnorrHandle)
+
+	return schnorr.SchnorrKeysharePublicKey(k.get())
+}
+
+func (k eddsaKeyshare) KeyID() ([]byte, error) {
+	defer runtime.KeepAlive(k.schnorrHandle)
+
+	return schnorr.SchnorrKeyshareKeyID(k.get())
+}
+
+func (k eddsaKeyshare) ChainCode() ([]byte, error) {
+	defer runtime.KeepAlive(k.schnorrHandle)
+
+	return schnorr.SchnorrKeyshareChainCode(k.get())
+}
+
+func (k eddsaKeyshare) Info() (KeyshareInfo, error) {
+	defer runtime.KeepAlive(k.schnorrHandle)
+
+	info, err := schnorr.SchnorrKeyshareInfo(k.get())
+	if err != nil {
+		return KeyshareInfo{}, err
+	}
+
+	return KeyshareInfo{Threshold: info.Threshold, Parties: info.Parties, PartyIndex: info.PartyIndex, PartyNames: k.names}, nil
+}
+
+// Close detaches the keyshare, after which it can no longer be used. The
+// bundled go-schnorr library has no function releasing keyshare handles, so the
+// native keyshare and its secret share stay allocated until the process exits.
+func (k eddsaKeyshare) Close() error {
+	return k.schnorrHandle.Close()
+}
+
+// redelivery works around go-schnorr sessions silently dropping a message of
+// the next round which arrives before the current round is complete. It keeps
+// the input of the session and offers all of it again whenever the session
+// starts a new round, which shows as new output; the session ignores messages
+// it has already processed. The output read to detect a new round is kept for
+// OutputMessage.
+type redelivery struct {
+	received [][]byte
+	output   [][]byte
+}
+
+func (r *redelivery) outputMessage(hnd schnorr.Handle, output func(schnorr.Handle) ([]byte, error)) ([]byte, error) {
+	if len(r.output) > 0 {
+		message := r.output[0]
+		r.output = r.output[1:]
+
+		return message, nil
+	}
+
+	return output(hnd)
+}
+
+func (r *redelivery) inputMessage(
+	hnd schnorr.Handle,
+	message []byte,
+	input func(schnorr.Handle, []byte) (bool, error),
+	output func(schnorr.Handle) ([]byte, error),
+) (bool, error) {
+	r.received = append(r.received, message)
+
+	finished, err := input(hnd, message)
+
+	for !finished && err == nil {
+		n := len(r.output)
+
+		for {
+			var out []byte
+			if out, err = output(hnd); err != nil || len(out) == 0 {
+				break
+			}
+
+			r.output = append(r.output, out)
+		}
+
+		if err != nil || len(r.output) == n {
+			break
+		}
+
+		for _, m := range r.received {
+			if finished, err = input(hnd, m); finished || err != nil {
+				break
+			}
+		}
+	}
+
+	if finished || err != nil {
+		r.received = nil
+	}
+
+	return finished, err
+}
+
+type eddsaKeygenSession struct {
+	*schnorrHandle
+	*redelivery
+	names []string
+}
+
+func (s eddsaKeygenSession) OutputMessage() ([]byte, error) {
+	defer runtime.KeepAlive(s.schnorrHandle)
+
+	return s.redelivery.outputMessage(s.get(), schnorr.SchnorrKeygenSessionOutputMessage)
+}
+
+func (s eddsaKeygenSession) MessageReceiver(message []byte, index int) (string, error) {
+	defer runtime.KeepAlive(s.schnorrHandle)
+
+	return schnorr.SchnorrKeygenSessionMessageReceiver(s.get(), message, uint32(index))
+}
+
+func (s eddsaKeygenSession) InputMessage(message []byte) (bool, error) {
+	defer runtime.KeepAlive(s.schnorrHandle)
+
+	return s.redelivery.inputMessage(s.get(), message, schnorr.SchnorrKeygenSessionInputMessage, schnorr.SchnorrKeygenSessionOutputMessage)
+}
+
+func (s eddsaKeygenSession) Finish() (Keyshare, error) {
+	defer runtime.KeepAlive(s.schnorrHandle)
+
+	share, err := schnorr.SchnorrKeygenSessionFinish(s.get())
+	if err != nil {
+		return nil, err
+	}
+
+	return eddsaKeyshare{newSchnorrHandle("keyshare", share, nil), s.names}, nil
+}
+
+type eddsaQcSession struct {
+	*schnorrHandle
+	*redelivery
+	names []string
+}
+
+func (s eddsaQcSession) OutputMessage() ([]byte, error) {
+	defer runtime.KeepAlive(s.schnorrHandle)
+
+	return s.redelivery.outputMessage(s.get(), schnorr.SchnorrQcSessionOutputMessage)
+}
+
+func (s eddsaQcSession) MessageReceiver(message []byte, index int) (string, error) {
+	defer runtime.KeepAlive(s.schnorrHandle)
+
+	return schnorr.SchnorrQcSessionMessageReceiver(s.get(), message, index)
+}
+
+func (s eddsaQcSession) InputMessage(message []byte) (bool, error) {
+	defer runtime.KeepAlive(s.schnorrHandle)
+
+	return s.redelivery.inputMessage(s.get(), message, schnorr.SchnorrQcSessionInputMessage, schnorr.SchnorrQcSessionOutputMessage)
+}
+
+func (s eddsaQcSession) Finish() (Keyshare, error) {
+	defer runtime.KeepAlive(s.schnorrHandle)
+
+	share, err := schnorr.SchnorrQcSessionFinish(s.get())
+	if err != nil {
+		return nil, err
+	}
+
+	return eddsaKeyshare{newSchnorrHandle("keyshare", share, nil), s.names}, nil
+}
+
+// eddsaSignSession verifies the signature against the public key of the
+// signing key before returning it.
+type eddsaSignSession struct {
+	*schnorrHandle
+	*redelivery
+	publicKey []byte
+	message   []byte
+}
+
+func (s eddsaSignSession) OutputMessage() ([]byte, error) {
+	defer runtime.KeepAlive(s.schnorrHandle)
+
+	return s.redelivery.outputMessage(s.get(), schnorr.SchnorrSignSessionOutputMessage)
+}
+
+func (s eddsaSignSession) MessageReceiver(message []byte, index int) (string, error) {
+	defer runtime.KeepAlive(s.schnorrHandle)
+
+	receiver, err := schnorr.SchnorrSignSessionMessageReceiver(s.get(), message, uint32(index))
+	if err != nil {
+		return "", err
+	}
+
+	return string(receiver), nil
+}
+
+func (s eddsaSignSession) InputMessage(message []byte) (bool, error) {
+	defer runtime.KeepAlive(s.schnorrHandle)
+
+	return s.redelivery.inputMessage(s.get(), message, schnorr.SchnorrSignSessionInputMessage, schnorr.SchnorrSignSessionOutputMessage)
+}
+
+func (s eddsaSignSession) Finish() ([]byte, error) {
+	defer runtime.KeepAlive(s.schnorrHandle)
+
+	signature, err := schnorr.SchnorrSignSessionFinish(s.get())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := schnorr.SchnorrVerifySignatureWithPublicKey(s.publicKey, s.message, signature); err != nil {
+		return nil, err
+	}
+
+	return signature, nil
+}
+
+type eddsaExportReceiver struct {
+	*schnorrHandle
+}
+
+func (r eddsaExportReceiver) InputMessage(message []byte) (bool, error) {
+	defer runtime.KeepAlive(r.schnorrHandle)
+
+	return schnorr.SchnorrKeyExportReceiverInputMessage(r.get(), message)
+}
+
+func (r eddsaExportReceiver) Finish() ([]byte, error) {
+	defer runtime.KeepAlive(r.schnorrHandle)
+
+	return schnorr.SchnorrKeyExportReceiverFinish(r.get())
+}
+
+func (eddsaScheme) Kind() Kind {
+	return KindEdDSA
+}
+
+func (eddsaScheme) KeygenSetupMsgNew(threshold int, keyID []byte, ids []string) ([]byte, error) {
+	return schnorr.SchnorrKeygenSetupMsgNew(int32(threshold), keyID, joinIDs(ids))
+}
+
+func (eddsaScheme) KeygenSessionFromSetup(setup []byte, id string) (KeygenSession, error) {
+	names, err := eddsaPartyNames(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	hnd, err := schnorr.SchnorrKeygenSessionFromSetup(setup, []byte(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return eddsaKeygenSession{newSchnorrHandle("keygen session", hnd, schnorr.SchnorrKeygenSessionFree), new(redelivery), names}, nil
+}
+
+func (eddsaScheme) KeyRefreshSessionFromSetup(setup []byte, id string, oldKeyshare Keyshare) (KeygenSession, error) {
+	names, err := eddsaPartyNames(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	share, err := EdDSAKeyshare(oldKeyshare)
+	if err != nil {
+		return nil, err
+	}
+
+	hnd, err := schnorr.SchnorrKeyRefreshSessionFromSetup(setup, []byte(id), share)
+	if err != nil {
+		return nil, err
+	}
+
+	return eddsaKeygenSession{newSchnorrHandle("keygen session", hnd, schnorr.SchnorrKeygenSessionFree), new(redelivery), names}, nil
+}
+
+func (eddsaScheme) KeyMigrateSessionFromSetup(setup []byte, id string, publicKey []byte, rootChainCode []byte, secretCoefficient []byte) (KeygenSession, error) {
+	names, err := eddsaPartyNames(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	hnd, err := schnorr.SchnorrKeyMigrateSessionFromSetup(setup, []byte(id), publicKey, rootChainCode, secretCoefficient)
+	if err != nil {
+		return nil, err
+	}
+
+	return eddsaKeygenSession{newSchnorrHandle("keygen session", hnd, schnorr.SchnorrKeygenSessionFree), new(redelivery), names}, nil
+}
+
+func (eddsaScheme) KeyshareToRefreshBytes(Keyshare) ([]byte, error) {
+	return nil, fmt.Errorf("%w: refresh shares", ErrUnsupported)
+}
+
+func (eddsaScheme) KeyRecoverySessionFromSetup([]byte, string, []byte) (KeygenSession, error) {
+	return nil, fmt.Errorf("%w: key recovery", ErrUnsupported)
+}
+
+func (eddsaScheme) QcSetupMsgNew(keyshare Keyshare, threshold int, ids []string, oldParties []int, newParties []int) ([]byte, error) {
+	share, err := EdDSAKeyshare(keyshare)
+	if err != nil {
+		return nil, err
+	}
+
+	return schnorr.SchnorrQcSetupMsgNew(share, threshold, ids, oldParties, newParties)
+}
+
+func (eddsaScheme) QcSessionFromSetup(setup []byte, id string, keyshare Keyshare) (KeygenSession, error) {
+	names, err := eddsaPartyNames(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	var share schnorr.Handle
+	if keyshare != nil {
+		var err error
+		if share, err = EdDSAKeyshare(keyshare); err != nil {
+			return nil, err
+		}
+	}
+
+	hnd, err := schnorr.SchnorrQcSessionFromSetup(setup, id, share)
+	if err != nil {
+		return nil, err
+	}
+
+	return eddsaQcSession{newSchnorrHandle("QC session", hnd, schnorr.SchnorrQcSessionFree), new(redelivery), names}, nil
+}
+
+func (eddsaScheme) SignSetupMsgNew(keyID []byte, chainPath []byte, message []byte, ids []string) ([]byte, error) {
+	return schnorr.SchnorrSignSetupMsgNew(keyID, chainPath, message, joinIDs(ids))
+}
+
+func (eddsaScheme) SignSessionFromSetup(setup []byte, id string, keyshare Keyshare) (SignSession, error) {
+	share, err := EdDSAKeyshare(keyshare)
+	if err != nil {
+		return nil, err
+	}
+
+	chainPath, err := schnorr.SchnorrDecodeChainPath(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := schnorr.SchnorrDecodeMessage(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := schnorr.SchnorrKeyshareDeriveChildPublicKey(share, chainPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The native session signs for the key of the keyshare it is given, so a
+	// child key is signed with a keyshare derived for this session only. It is
+	// never handed out and, without a native free, stays allocated until the
+	// process exits.
+	if string(chainPath) != "m" {
+		if share, err = schnorr.SchnorrKeyshareDeriveChild(share, chainPath); err != nil {
+			return nil, err
+		}
+	}
+
+	hnd, err := schnorr.SchnorrSignSessionFromSetup(setup, []byte(id), share)
+	if err != nil {
+		return nil, err
+	}
+
+	return eddsaSignSession{schnorrHandle: newSchnorrHandle("sign session", hnd, schnorr.SchnorrSignSessionFree), redelivery: new(redelivery), publicKey: publicKey, message: message}, nil
+}
+
+func (eddsaScheme) KeyExportReceiverNew(keyshare Keyshare, ids []string) (ExportReceiver, []byte, error) {
+	share, err := EdDSAKeyshare(keyshare)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hnd, setup, err := schnorr.SchnorrKeyExportReceiverNew(share, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return eddsaExportReceiver{newSchnorrHandle("export receiver", hnd, nil)}, setup, nil
+}
+
+func (eddsaScheme) KeyExporter(keyshare Keyshare, id string, setup []byte) ([]byte, string, error) {
+	share, err := EdDSAKeyshare(keyshare)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return schnorr.SchnorrKeyExporter(share, id, setup)
+}
+
+func (eddsaScheme) KeyImportInitiatorNew(privateKey []byte, rootChain []byte, threshold int, ids []string) (KeygenSession, []byte, error) {
+	hnd, setup, err := schnorr.SchnorrKeyImportInitiatorNew(privateKey, rootChain, uint8(threshold), ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return eddsaKeygenSession{newSchnorrHandle("keygen session", hnd, schnorr.SchnorrKeygenSessionFree), new(redelivery), ids}, setup, nil
+}
+
+func (eddsaScheme) KeyImporterNew(setup []byte, id string) (KeygenSession, error) {
+	names, err := eddsaPartyNames(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	hnd, err := schnorr.SchnorrKeyImporterNew(setup, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return eddsaKeygenSession{newSchnorrHandle("keygen session", hnd, schnorr.SchnorrKeygenSessionFree), new(redelivery), names}, nil
+}
+
+func (eddsaScheme) KeyshareFromBytes(buf []byte) (Keyshare, error) {
+	hnd, err := schnorr.SchnorrKeyshareFromBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEdDSAKeyshare(hnd), nil
+}
+
+// eddsaPartyNames returns the names of the parties of the keyshares produced by
+// a keygen or QC setup message, by party index.
+func eddsaPartyNames(setup []byte) ([]string, error) {
+	decoded, err := schnorr.SchnorrDecodeSetup(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	return setupPartyNames(decoded.Parties, decoded.NewParties), nil
+}
@@ -0,0 +1,278 @@
+// Package envelope encrypts protocol messages end to end, so that relays
+// and other transports only ever see ciphertext.
+//
+// A Transport wraps any mpc.Transport. Every outbound message is sealed with
+// AES-256-GCM under a key shared with its receiver, with the session ID, the
+// sender, the receiver and a per-sender sequence number bound as associated
+// data. Inbound messages are opened with the key shared with their sender.
+// Tampered, misdirected and replayed messages are dropped and counted rather
+// than failing the session, since relays re-deliver messages and anyone on the
+// path can inject packets. Sessions of both go-dkls and go-schnorr run
+// unchanged on top of it.
+//
+// With a symmetric session key every party holds the key of every message, so
+// the envelope keeps outsiders out but does not authenticate the sender among
+// the parties: any party can seal a message as if it came from another one.
+// Use pairwise X25519 keys, or mpc/identity signatures, to authenticate senders.
+//
+// Key functionalities include:
+// - Sealing messages with a per-session symmetric key
+// - Sealing messages with pairwise keys agreed from per-party X25519 keys
+// - Dropping tampered, misdirected and replayed messages
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/vultisig/go-wrappers/mpc"
+)
+
+const (
+	version    = 1
+	headerSize = 1 + 8
+	keySize    = 32
+)
+
+// Reasons for dropping an inbound message, passed to Transport.OnDrop.
+var (
+	// ErrMalformed is reported for inbound data which is not an envelope.
+	ErrMalformed = errors.New("envelope: malformed message")
+	// ErrAuth is reported when an inbound message fails authentication.
+	ErrAuth = errors.New("envelope: message authentication failed")
+	// ErrReplay is reported when an inbound message has already been received.
+	ErrReplay = errors.New("envelope: replayed message")
+	// ErrUnknownPeer is returned when there is no key for a peer.
+	ErrUnknownPeer = errors.New("envelope: unknown peer")
+)
+
+// Transport is an mpc.Transport sealing the messages of an inner transport.
+type Transport struct {
+	// OnDrop, if set, is called with the sender and the reason of every inbound
+	// message which is dropped. It must not block.
+	OnDrop func(sender string, err error)
+
+	inner     mpc.Transport
+	sessionID string
+	partyID   string
+	aead      func(peer string) (cipher.AEAD, error)
+
+	mu      sync.Mutex
+	seq     uint64
+	seen    map[string]map[uint64]struct{}
+	dropped uint64
+}
+
+// NewSymmetric wraps a transport with a key shared by all parties of the session.
+//
+// Every holder of the key can seal a message under the ID of another party, so
+// the sender of a message is only authenticated as some party of the session.
+//
+// Parameters:
+//   - inner: mpc.Transport - the transport carrying the sealed messages.
+//   - sessionID: string - the ID of the session, bound to every message.
+//   - partyID: string - the ID of the local party.
+//   - key: []byte - the 32-byte session key.
+//
+// Returns:
+//   - *Transport: the sealing transport.
+//   - error: an error if the key has the wrong size.
+func NewSymmetric(inner mpc.Transport, sessionID string, partyID string, key []byte) (*Transport, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("envelope: session key must be %d bytes, got %d", keySize, len(key))
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTransport(inner, sessionID, partyID, func(string) (cipher.AEAD, error) {
+		return aead, nil
+	}), nil
+}
+
+// NewX25519 wraps a transport with pairwise keys agreed between the X25519 key
+// of the local party and the X25519 keys of its peers. Only the sender and the
+// receiver of a message can open it.
+//
+// Parameters:
+//   - inner: mpc.Transport - the transport carrying the sealed messages.
+//   - sessionID: string - the ID of the session, bound to every message and key.
+//   - partyID: string - the ID of the local party.
+//   - privateKey: *ecdh.PrivateKey - the X25519 key of the local party.
+//   - peers: map[string]*ecdh.PublicKey - the X25519 keys of the peers, keyed by party ID.
+//
+// Returns:
+//   - *Transport: the sealing transport.
+//   - error: an error if a key agreement fails.
+func NewX25519(inner mpc.Transport, sessionID string, partyID string, privateKey *ecdh.PrivateKey, peers map[string]*ecdh.PublicKey) (*Transport, error) {
+	aeads := make(map[string]cipher.AEAD, len(peers))
+
+	for id, publicKey := range peers {
+		shared, err := privateKey.ECDH(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("envelope: key agreement with %s: %w", id, err)
+		}
+
+		h := sha256.New()
+		h.Write([]byte("vultisig/envelope/x25519"))
+		h.Write(shared)
+		h.Write([]byte(sessionID))
+
+		if aeads[id], err = newAEAD(h.Sum(nil)); err != nil {
+			return nil, err
+		}
+	}
+
+	return newTransport(inner, sessionID, partyID, func(peer string) (cipher.AEAD, error) {
+		aead, ok := aeads[peer]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPeer, peer)
+		}
+
+		return aead, nil
+	}), nil
+}
+
+func newTransport(inner mpc.Transport, sessionID string, partyID string, aead func(string) (cipher.AEAD, error)) *Transport {
+	return &Transport{
+		inner:     inner,
+		sessionID: sessionID,
+		partyID:   partyID,
+		aead:      aead,
+		seen:      make(map[string]map[uint64]struct{}),
+	}
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// additionalData binds the session, the direction and the sequence number of a message.
+func (t *Transport) additionalData(sender string, receiver string, header []byte) []byte {
+	var ad []byte
+	for _, s := range []string{t.sessionID, sender, receiver} {
+		ad = binary.BigEndian.AppendUint32(ad, uint32(len(s)))
+		ad = append(ad, s...)
+	}
+
+	return append(ad, header...)
+}
+
+// Send seals the message for the receiver and passes it to the inner transport.
+func (t *Transport) Send(ctx context.Context, receiver string, message []byte) error {
+	aead, err := t.aead(receiver)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.seq++
+	seq := t.seq
+	t.mu.Unlock()
+
+	header := make([]byte, headerSize, headerSize+aead.NonceSize()+len(message)+aead.Overhead())
+	header[0] = version
+	binary.BigEndian.PutUint64(header[1:], seq)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	sealed := append(header, nonce...)
+	sealed = aead.Seal(sealed, nonce, message, t.additionalData(t.partyID, receiver, header))
+
+	return t.inner.Send(ctx, receiver, sealed)
+}
+
+// Receive returns the next message of the inner transport, opened. Messages
+// which are malformed, fail authentication, come from an unknown peer or are
+// replayed are dropped, reported to OnDrop and counted, and Receive waits for
+// the next message. Only errors of the inner transport are returned.
+func (t *Transport) Receive(ctx context.Context) (string, []byte, error) {
+	for {
+		sender, sealed, err := t.inner.Receive(ctx)
+		if err != nil {
+			return "", nil, err
+		}
+
+		message, err := t.open(sender, sealed)
+		if err != nil {
+			t.drop(sender, err)
+			continue
+		}
+
+		return sender, message, nil
+	}
+}
+
+// Dropped returns the number of inbound messages which have been dropped.
+func (t *Transport) Dropped() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.dropped
+}
+
+func (t *Transport) drop(sender string, err error) {
+	t.mu.Lock()
+	t.dropped++
+	t.mu.Unlock()
+
+	if t.OnDrop != nil {
+		t.OnDrop(sender, err)
+	}
+}
+
+// open authenticates and decrypts a sealed message and checks it is not a replay.
+func (t *Transport) open(sender string, sealed []byte) ([]byte, error) {
+	aead, err := t.aead(sender)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < headerSize+aead.NonceSize()+aead.Overhead() || sealed[0] != version {
+		return nil, fmt.Errorf("%w from %s", ErrMalformed, sender)
+	}
+
+	header := sealed[:headerSize]
+	nonce := sealed[headerSize : headerSize+aead.NonceSize()]
+	seq := binary.BigEndian.Uint64(header[1:])
+
+	message, err := aead.Open(nil, nonce, sealed[headerSize+aead.NonceSize():], t.additionalData(sender, t.partyID, header))
+	if err != nil {
+		return nil, fmt.Errorf("%w from %s", ErrAuth, sender)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	seen, ok := t.seen[sender]
+	if !ok {
+		seen = make(map[uint64]struct{})
+		t.seen[sender] = seen
+	}
+
+	if _, ok := seen[seq]; ok {
+		return nil, fmt.Errorf("%w from %s: sequence %d", ErrReplay, sender, seq)
+	}
+
+	seen[seq] = struct{}{}
+
+	return message, nil
+}
@@ -0,0 +1,200 @@
+package envelope_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"sync"
+	"testing"
+
+	"github.com/vultisig/go-wrappers/mpc"
+	"github.com/vultisig/go-wrappers/mpc/envelope"
+	"github.com/vultisig/go-wrappers/mpc/router"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tap records the sealed messages passing through a transport.
+type tap struct {
+	mpc.Transport
+
+	mu   sync.Mutex
+	sent [][]byte
+}
+
+func (t *tap) Send(ctx context.Context, receiver string, message []byte) error {
+	t.mu.Lock()
+	t.sent = append(t.sent, message)
+	t.mu.Unlock()
+
+	return t.Transport.Send(ctx, receiver, message)
+}
+
+func TestX25519Keygen(t *testing.T) {
+	t.Parallel()
+
+	ids := []string{"p1", "p2", "p3"}
+	r := router.New(ids)
+
+	keys := make(map[string]*ecdh.PrivateKey, len(ids))
+	for _, id := range ids {
+		key, err := ecdh.X25519().GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+
+		keys[id] = key
+	}
+
+	setup, err := mpc.EdDSA.KeygenSetupMsgNew(2, nil, ids)
+	assert.NoError(t, err)
+
+	shares := make([]mpc.Keyshare, len(ids))
+	taps := make([]*tap, len(ids))
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		peers := make(map[string]*ecdh.PublicKey)
+		for _, peer := range ids {
+			if peer != id {
+				peers[peer] = keys[peer].PublicKey()
+			}
+		}
+
+		taps[i] = &tap{Transport: r.Transport(id)}
+
+		transport, err := envelope.NewX25519(taps[i], "session", id, keys[id], peers)
+		assert.NoError(t, err)
+
+		session, err := mpc.EdDSA.KeygenSessionFromSetup(setup, id)
+		assert.NoError(t, err)
+
+		wg.Add(1)
+
+		go func(i int, id string) {
+			defer wg.Done()
+
+			d := &mpc.Driver{ID: id, Parties: ids, Transport: transport}
+
+			var err error
+			shares[i], err = mpc.Drive[mpc.Keyshare](context.Background(), d, session)
+			assert.NoError(t, err)
+		}(i, id)
+	}
+
+	wg.Wait()
+
+	publicKey, err := shares[0].PublicKey()
+	assert.NoError(t, err)
+
+	for _, share := range shares {
+		pk, err := share.PublicKey()
+		assert.NoError(t, err)
+		assert.Equal(t, publicKey, pk)
+	}
+
+	// The relay never sees the public key in the clear.
+	for _, tp := range taps {
+		for _, sealed := range tp.sent {
+			assert.False(t, bytes.Contains(sealed, publicKey))
+		}
+	}
+}
+
+func TestDropsRejected(t *testing.T) {
+	t.Parallel()
+
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		tamper func(r *router.Router, sealed []byte)
+		// accepted is the number of messages opened before the rejected one.
+		accepted int
+		err      error
+	}{
+		{
+			name: "replay",
+			tamper: func(r *router.Router, sealed []byte) {
+				_ = r.Transport("alice").Send(context.Background(), "bob", sealed)
+				_ = r.Transport("alice").Send(context.Background(), "bob", sealed)
+			},
+			accepted: 1,
+			err:      envelope.ErrReplay,
+		},
+		{
+			name: "flipped bit",
+			tamper: func(r *router.Router, sealed []byte) {
+				sealed[len(sealed)-1] ^= 1
+				_ = r.Transport("alice").Send(context.Background(), "bob", sealed)
+			},
+			err: envelope.ErrAuth,
+		},
+		{
+			name: "spoofed sender",
+			tamper: func(r *router.Router, sealed []byte) {
+				_ = r.Transport("carol").Send(context.Background(), "bob", sealed)
+			},
+			err: envelope.ErrAuth,
+		},
+		{
+			name: "truncated",
+			tamper: func(r *router.Router, sealed []byte) {
+				_ = r.Transport("alice").Send(context.Background(), "bob", sealed[:8])
+			},
+			err: envelope.ErrMalformed,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			relay := router.New([]string{"bob"})
+			r := router.New([]string{"alice", "bob", "carol"})
+
+			alice, err := envelope.NewSymmetric(relay.Transport("alice"), "session", "alice", key)
+			assert.NoError(t, err)
+
+			bob, err := envelope.NewSymmetric(r.Transport("bob"), "session", "bob", key)
+			assert.NoError(t, err)
+
+			// Intercept the sealed message on its way to bob.
+			assert.NoError(t, alice.Send(context.Background(), "bob", []byte("secret")))
+
+			_, sealed, err := relay.Transport("bob").Receive(context.Background())
+			assert.NoError(t, err)
+
+			tc.tamper(r, sealed)
+
+			var dropErr error
+
+			bob.OnDrop = func(sender string, err error) {
+				dropErr = err
+			}
+
+			// A genuine message following the rejected one is still received.
+			assert.NoError(t, alice.Send(context.Background(), "bob", []byte("after")))
+
+			_, next, err := relay.Transport("bob").Receive(context.Background())
+			assert.NoError(t, err)
+			assert.NoError(t, r.Transport("alice").Send(context.Background(), "bob", next))
+
+			for i := 0; i < tc.accepted; i++ {
+				_, msg, err := bob.Receive(context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, []byte("secret"), msg)
+			}
+
+			sender, msg, err := bob.Receive(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "alice", sender)
+			assert.Equal(t, []byte("after"), msg)
+
+			assert.ErrorIs(t, dropErr, tc.err)
+			assert.Equal(t, uint64(1), bob.Dropped())
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Package identity authenticates the sender of every protocol message.
+//
+// Each device holds a long-term Ed25519 signing key bound to its party ID.
+// A Transport wraps any mpc.Transport, signs every outbound message together
+// with the session ID, the sender and the receiver, and verifies inbound
+// messages against the Directory of known parties before they are handed to
+// the session. A message which fails verification is reported as an
+// *ImpostorError naming the party it claims to come from.
+//
+// Key functionalities include:
+// - Long-term Ed25519 identities bound to party IDs
+// - Signing outbound and verifying inbound protocol messages
+// - Typed errors naming impostors
+package identity
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/vultisig/go-wrappers/mpc"
+)
+
+// ErrImpostor matches every *ImpostorError.
+var ErrImpostor = errors.New("identity: impostor")
+
+// ImpostorError is returned when an inbound message is not signed by the party
+// it claims to come from.
+type ImpostorError struct {
+	// Claimed is the party ID the message claims to come from.
+	Claimed string
+	// Reason describes why the verification failed.
+	Reason string
+}
+
+func (e *ImpostorError) Error() string {
+	return fmt.Sprintf("identity: message claiming to come from %s rejected: %s", e.Claimed, e.Reason)
+}
+
+func (e *ImpostorError) Is(target error) bool {
+	return target == ErrImpostor
+}
+
+// Identity is the long-term signing identity of a device.
+type Identity struct {
+	// ID is the party ID bound to the key.
+	ID string
+	// PrivateKey is the Ed25519 signing key.
+	PrivateKey ed25519.PrivateKey
+}
+
+// Generate creates a new identity with a random signing key.
+//
+// Parameters:
+//   - id: string - the party ID bound to the key.
+//
+// Returns:
+//   - Identity: the identity.
+//   - error: an error if the key can not be generated.
+func Generate(id string) (Identity, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	return Identity{ID: id, PrivateKey: privateKey}, nil
+}
+
+// PublicKey returns the public key of the identity.
+func (i Identity) PublicKey() ed25519.PublicKey {
+	return i.PrivateKey.Public().(ed25519.PublicKey)
+}
+
+// Directory maps party IDs to the public keys of their identities.
+type Directory map[string]ed25519.PublicKey
+
+// Transport is an mpc.Transport signing and verifying the messages of an inner transport.
+type Transport struct {
+	inner     mpc.Transport
+	sessionID string
+	self      Identity
+	directory Directory
+}
+
+// NewTransport wraps a transport with message signing and verification.
+//
+// Parameters:
+//   - inner: mpc.Transport - the transport carrying the signed messages.
+//   - sessionID: string - the ID of the session, bound to every signature.
+//   - self: Identity - the identity of the local party.
+//   - directory: Directory - the public keys of the peers.
+//
+// Returns:
+//   - *Transport: the authenticating transport.
+func NewTransport(inner mpc.Transport, sessionID string, self Identity, directory Directory) *Transport {
+	return &Transport{inner: inner, sessionID: sessionID, self: self, directory: directory}
+}
+
+// signedData is the data covered by the signature of a message.
+func (t *Transport) signedData(sender string, receiver string, message []byte) []byte {
+	data := []byte("vultisig/identity/v1")
+	for _, s := range []string{t.sessionID, sender, receiver} {
+		data = binary.BigEndian.AppendUint32(data, uint32(len(s)))
+		data = append(data, s...)
+	}
+
+	return append(data, message...)
+}
+
+// Send signs the message and passes it to the inner transport.
+func (t *Transport) Send(ctx context.Context, receiver string, message []byte) error {
+	signature := ed25519.Sign(t.self.PrivateKey, t.signedData(t.self.ID, receiver, message))
+
+	return t.inner.Send(ctx, receiver, append(signature, message...))
+}
+
+// Receive returns the next message of the inner transport once its signature is verified.
+func (t *Transport) Receive(ctx context.Context) (string, []byte, error) {
+	sender, signed, err := t.inner.Receive(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	publicKey, ok := t.directory[sender]
+	if !ok {
+		return "", nil, &ImpostorError{Claimed: sender, Reason: "unknown party"}
+	}
+
+	if len(signed) < ed25519.SignatureSize {
+		return "", nil, &ImpostorError{Claimed: sender, Reason: "missing signature"}
+	}
+
+	signature, message := signed[:ed25519.SignatureSize], signed[ed25519.SignatureSize:]
+	if !ed25519.Verify(publicKey, t.signedData(sender, t.self.ID, message), signature) {
+		return "", nil, &ImpostorError{Claimed: sender, Reason: "invalid signature"}
+	}
+
+	return sender, message, nil
+}
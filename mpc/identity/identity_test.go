@@ -0,0 +1,124 @@
+package identity_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vultisig/go-wrappers/mpc"
+	"github.com/vultisig/go-wrappers/mpc/identity"
+	"github.com/vultisig/go-wrappers/mpc/router"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeygen(t *testing.T) {
+	t.Parallel()
+
+	ids := []string{"p1", "p2", "p3"}
+	r := router.New(ids)
+
+	directory := identity.Directory{}
+	identities := make(map[string]identity.Identity, len(ids))
+
+	for _, id := range ids {
+		self, err := identity.Generate(id)
+		assert.NoError(t, err)
+
+		identities[id] = self
+		directory[id] = self.PublicKey()
+	}
+
+	setup, err := mpc.EdDSA.KeygenSetupMsgNew(2, nil, ids)
+	assert.NoError(t, err)
+
+	sessions := make([]mpc.KeygenSession, len(ids))
+	for i, id := range ids {
+		sessions[i], err = mpc.EdDSA.KeygenSessionFromSetup(setup, id)
+		assert.NoError(t, err)
+	}
+
+	errs := make(chan error, len(ids))
+	for i, id := range ids {
+		go func(id string, session mpc.KeygenSession) {
+			transport := identity.NewTransport(r.Transport(id), "session", identities[id], directory)
+			d := &mpc.Driver{ID: id, Parties: ids, Transport: transport}
+
+			_, err := mpc.Drive[mpc.Keyshare](context.Background(), d, session)
+			errs <- err
+		}(id, sessions[i])
+	}
+
+	for range ids {
+		assert.NoError(t, <-errs)
+	}
+}
+
+func TestImpostor(t *testing.T) {
+	t.Parallel()
+
+	alice, err := identity.Generate("alice")
+	assert.NoError(t, err)
+
+	bob, err := identity.Generate("bob")
+	assert.NoError(t, err)
+
+	mallory, err := identity.Generate("alice")
+	assert.NoError(t, err)
+
+	directory := identity.Directory{"alice": alice.PublicKey(), "bob": bob.PublicKey()}
+
+	testCases := []struct {
+		name   string
+		send   func(r *router.Router) error
+		reason string
+	}{
+		{
+			name: "wrong key",
+			send: func(r *router.Router) error {
+				return identity.NewTransport(r.Transport("alice"), "session", mallory, directory).
+					Send(context.Background(), "bob", []byte("msg"))
+			},
+			reason: "invalid signature",
+		},
+		{
+			name: "other session",
+			send: func(r *router.Router) error {
+				return identity.NewTransport(r.Transport("alice"), "other", alice, directory).
+					Send(context.Background(), "bob", []byte("msg"))
+			},
+			reason: "invalid signature",
+		},
+		{
+			name: "unsigned",
+			send: func(r *router.Router) error {
+				return r.Transport("alice").Send(context.Background(), "bob", []byte("msg"))
+			},
+			reason: "missing signature",
+		},
+		{
+			name: "unknown party",
+			send: func(r *router.Router) error {
+				return r.Transport("carol").Send(context.Background(), "bob", make([]byte, 80))
+			},
+			reason: "unknown party",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := router.New([]string{"alice", "bob", "carol"})
+			assert.NoError(t, tc.send(r))
+
+			_, _, err := identity.NewTransport(r.Transport("bob"), "session", bob, directory).Receive(context.Background())
+			assert.ErrorIs(t, err, identity.ErrImpostor)
+
+			var impostor *identity.ImpostorError
+			assert.True(t, errors.As(err, &impostor))
+			assert.Equal(t, tc.reason, impostor.Reason)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Package inbox guards the input of protocol sessions against duplicate delivery.
+//
+// An Inbox sits in front of the InputMessage call of any session of go-dkls or
+// go-schnorr: keygen, refresh, migration, QC, import, sign and export. It drops
+// messages it has already delivered, so that relays and clients may re-deliver
+// freely. Memory is bounded and every drop is counted.
+//
+// Messages which arrive before their round need no handling here: the native
+// sessions of go-dkls and go-schnorr buffer them and process them once their
+// round starts, so they never reject a message for arriving early. Any error of
+// a session, such as a protocol abort, is returned unchanged, and the inbox
+// stops feeding the session.
+//
+// Key functionalities include:
+// - Deduplication by SHA-256 digest
+// - Bounded memory with metrics on drops
+package inbox
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	"github.com/vultisig/go-wrappers/mpc"
+)
+
+// DefaultMaxSeen is the default number of digests remembered for deduplication.
+const DefaultMaxSeen = 4096
+
+// Input is anything accepting protocol messages, e.g. an mpc.Session, an
+// mpc.ExportReceiver or an InputFunc around a raw handle.
+type Input interface {
+	InputMessage(message []byte) (bool, error)
+}
+
+// InputFunc adapts a function to Input, e.g.
+//
+//	inbox.InputFunc(func(msg []byte) (bool, error) {
+//		return session.DklsKeygenSessionInputMessage(hnd, msg)
+//	})
+type InputFunc func(message []byte) (bool, error)
+
+func (f InputFunc) InputMessage(message []byte) (bool, error) {
+	return f(message)
+}
+
+// Config bounds an Inbox. Zero values select the defaults.
+type Config struct {
+	// MaxSeen is the number of digests remembered; the oldest is forgotten first.
+	MaxSeen int
+	// OnDrop, if set, is called for every message dropped as a duplicate.
+	OnDrop func(message []byte)
+}
+
+// Metrics counts what an Inbox did with its messages.
+type Metrics struct {
+	// Accepted is the number of messages delivered to the session.
+	Accepted uint64
+	// Duplicates is the number of messages dropped as duplicates.
+	Duplicates uint64
+	// Forgotten is the number of digests forgotten to stay within MaxSeen. A
+	// message re-delivered after its digest was forgotten reaches the session again.
+	Forgotten uint64
+}
+
+// Inbox deduplicates the input of a session. It is safe for concurrent use.
+type Inbox struct {
+	target Input
+	cfg    Config
+
+	mu       sync.Mutex
+	finished bool
+	// err is the first error of the session.
+	err     error
+	seen    map[[sha256.Size]byte]struct{}
+	order   [][sha256.Size]byte
+	metrics Metrics
+}
+
+// New creates an inbox in front of the input of a session.
+//
+// Parameters:
+//   - target: Input - the session receiving the messages.
+//   - cfg: Config - the bounds of the inbox.
+//
+// Returns:
+//   - *Inbox: the inbox.
+func New(target Input, cfg Config) *Inbox {
+	if cfg.MaxSeen <= 0 {
+		cfg.MaxSeen = DefaultMaxSeen
+	}
+
+	return &Inbox{target: target, cfg: cfg, seen: make(map[[sha256.Size]byte]struct{})}
+}
+
+// InputMessage offers a message to the session unless it was delivered
+// before. An error of the session is returned, and every later call returns it
+// again without reaching the session.
+//
+// Parameters:
+//   - message: []byte - the protocol message.
+//
+// Returns:
+//   - bool: true once the session has finished.
+//   - error: the error of the session, if it failed.
+func (b *Inbox) InputMessage(message []byte) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return false, b.err
+	}
+
+	if b.finished {
+		return true, nil
+	}
+
+	digest := sha256.Sum256(message)
+	if _, ok := b.seen[digest]; ok {
+		b.metrics.Duplicates++
+
+		if b.cfg.OnDrop != nil {
+			b.cfg.OnDrop(message)
+		}
+
+		return false, nil
+	}
+
+	finished, err := b.target.InputMessage(message)
+	if err != nil {
+		b.err = err
+
+		return false, err
+	}
+
+	b.accept(digest)
+	b.finished = finished
+
+	return finished, nil
+}
+
+// accept remembers the digest of a delivered message. b.mu must be held.
+func (b *Inbox) accept(digest [sha256.Size]byte) {
+	b.metrics.Accepted++
+
+	b.seen[digest] = struct{}{}
+	b.order = append(b.order, digest)
+
+	if len(b.order) > b.cfg.MaxSeen {
+		delete(b.seen, b.order[0])
+		b.order = b.order[1:]
+		b.metrics.Forgotten++
+	}
+}
+
+// Metrics returns a snapshot of the counters of the inbox.
+func (b *Inbox) Metrics() Metrics {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.metrics
+}
+
+// Session is a session whose input goes through an Inbox.
+type Session[T any] struct {
+	mpc.Finisher[T]
+	inbox *Inbox
+}
+
+// Wrap puts an inbox in front of the input of a session. The result runs
+// under mpc.Drive like the session itself.
+//
+// Parameters:
+//   - session: mpc.Finisher[T] - the session.
+//   - cfg: Config - the bounds of the inbox.
+//
+// Returns:
+//   - *Session[T]: the wrapped session.
+func Wrap[T any](session mpc.Finisher[T], cfg Config) *Session[T] {
+	return &Session[T]{Finisher: session, inbox: New(session, cfg)}
+}
+
+func (s *Session[T]) InputMessage(message []byte) (bool, error) {
+	return s.inbox.InputMessage(message)
+}
+
+// Inbox returns the inbox of the session.
+func (s *Session[T]) Inbox() *Inbox {
+	return s.inbox
+}
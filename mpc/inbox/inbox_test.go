@@ -0,0 +1,316 @@
+package inbox_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vultisig/go-wrappers/mpc"
+	"github.com/vultisig/go-wrappers/mpc/inbox"
+	"github.com/vultisig/go-wrappers/mpc/router"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rounds accepts any message and finishes with the message last.
+func rounds(last byte) inbox.InputFunc {
+	return func(message []byte) (bool, error) {
+		return message[0] == last, nil
+	}
+}
+
+func TestInbox(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		cfg      inbox.Config
+		input    []string
+		finished bool
+		metrics  inbox.Metrics
+	}{
+		{
+			name:     "in order",
+			input:    []string{"1", "2", "3"},
+			finished: true,
+			metrics:  inbox.Metrics{Accepted: 3},
+		},
+		{
+			name:     "duplicates",
+			input:    []string{"1", "1", "2", "1", "2", "3"},
+			finished: true,
+			metrics:  inbox.Metrics{Accepted: 3, Duplicates: 3},
+		},
+		{
+			name:    "forgotten",
+			cfg:     inbox.Config{MaxSeen: 1},
+			input:   []string{"1", "2", "1", "1"},
+			metrics: inbox.Metrics{Accepted: 3, Duplicates: 1, Forgotten: 2},
+		},
+		{
+			name:     "after finish",
+			input:    []string{"3", "1"},
+			finished: true,
+			metrics:  inbox.Metrics{Accepted: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var dropped [][]byte
+			tc.cfg.OnDrop = func(message []byte) {
+				dropped = append(dropped, message)
+			}
+
+			b := inbox.New(rounds('3'), tc.cfg)
+
+			var finished bool
+			for _, msg := range tc.input {
+				f, err := b.InputMessage([]byte(msg))
+				assert.NoError(t, err)
+
+				finished = finished || f
+			}
+
+			assert.Equal(t, tc.finished, finished)
+			assert.Equal(t, tc.metrics, b.Metrics())
+			assert.Len(t, dropped, int(tc.metrics.Duplicates))
+		})
+	}
+}
+
+// duplicating delivers every message twice.
+type duplicating struct {
+	mpc.Transport
+}
+
+func (d duplicating) Send(ctx context.Context, receiver string, message []byte) error {
+	if err := d.Transport.Send(ctx, receiver, message); err != nil {
+		return err
+	}
+
+	return d.Transport.Send(ctx, receiver, message)
+}
+
+func TestWrapKeygen(t *testing.T) {
+	t.Parallel()
+
+	ids := []string{"p1", "p2", "p3"}
+	r := router.New(ids)
+
+	setup, err := mpc.ECDSA.KeygenSetupMsgNew(2, nil, ids)
+	assert.NoError(t, err)
+
+	sessions := make([]*inbox.Session[mpc.Keyshare], len(ids))
+	for i, id := range ids {
+		session, err := mpc.ECDSA.KeygenSessionFromSetup(setup, id)
+		assert.NoError(t, err)
+
+		sessions[i] = inbox.Wrap[mpc.Keyshare](session, inbox.Config{})
+	}
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+
+		go func(id string, session *inbox.Session[mpc.Keyshare]) {
+			defer wg.Done()
+
+			d := &mpc.Driver{ID: id, Parties: ids, Transport: duplicating{r.Transport(id)}}
+
+			share, err := mpc.Drive[mpc.Keyshare](context.Background(), d, session)
+			assert.NoError(t, err)
+			assert.NoError(t, share.Close())
+		}(id, sessions[i])
+	}
+
+	wg.Wait()
+
+	for _, s := range sessions {
+		metrics := s.Inbox().Metrics()
+		assert.NotZero(t, metrics.Accepted)
+		assert.NotZero(t, metrics.Duplicates)
+	}
+}
+
+func TestInboxSessionError(t *testing.T) {
+	t.Parallel()
+
+	errAbort := errors.New("protocol aborted")
+
+	calls := 0
+	b := inbox.New(inbox.InputFunc(func(message []byte) (bool, error) {
+		calls++
+
+		if message[0] == 'x' {
+			return false, errAbort
+		}
+
+		return false, nil
+	}), inbox.Config{})
+
+	_, err := b.InputMessage([]byte("1"))
+	assert.NoError(t, err)
+
+	_, err = b.InputMessage([]byte("x"))
+	assert.ErrorIs(t, err, errAbort)
+
+	// The failed session is not fed again.
+	_, err = b.InputMessage([]byte("2"))
+	assert.ErrorIs(t, err, errAbort)
+	assert.Equal(t, 2, calls)
+}
+
+// holding delays the first message it receives until two later messages from
+// another sender have arrived, so that it is delivered after a message of the
+// following round.
+type holding struct {
+	mpc.Transport
+
+	held    []byte
+	from    string
+	others  int
+	pending []message
+}
+
+type message struct {
+	sender string
+	body   []byte
+}
+
+func (h *holding) Receive(ctx context.Context) (string, []byte, error) {
+	for {
+		if len(h.pending) != 0 {
+			msg := h.pending[0]
+			h.pending = h.pending[1:]
+
+			return msg.sender, msg.body, nil
+		}
+
+		sender, body, err := h.Transport.Receive(ctx)
+		if err != nil {
+			return "", nil, err
+		}
+
+		switch {
+		case h.from == "":
+			h.from, h.held = sender, body
+			continue
+		case h.held != nil && sender != h.from:
+			h.others++
+			if h.others == 2 {
+				h.pending = append(h.pending, message{sender: h.from, body: h.held})
+				h.held = nil
+			}
+		}
+
+		return sender, body, nil
+	}
+}
+
+// runHolding runs the sessions of all parties, holding back the first message
+// p1 receives so that it is delivered after a message of the following round.
+func runHolding[T any](t *testing.T, ids []string, sessions []mpc.Finisher[T]) ([]T, bool) {
+	r := router.New(ids)
+
+	results := make([]T, len(ids))
+	errs := make([]error, len(ids))
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+
+		go func(i int, id string, session *inbox.Session[T]) {
+			defer wg.Done()
+
+			transport := r.Transport(id)
+			if id == "p1" {
+				transport = &holding{Transport: transport}
+			}
+
+			d := &mpc.Driver{ID: id, Parties: ids, Transport: transport, Timeout: time.Minute}
+
+			results[i], errs[i] = mpc.Drive[T](context.Background(), d, session)
+		}(i, id, inbox.Wrap[T](sessions[i], inbox.Config{}))
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if !assert.NoError(t, err) {
+			return nil, false
+		}
+	}
+
+	return results, true
+}
+
+// TestWrapOutOfOrder checks that sessions of both schemes tolerate a message
+// delivered after a message of the following round: go-dkls buffers it, and the
+// EdDSA sessions of mpc deliver it again once go-schnorr reaches its round.
+func TestWrapOutOfOrder(t *testing.T) {
+	t.Parallel()
+
+	msg := make([]byte, 32)
+	for i := range msg {
+		msg[i] = 7
+	}
+
+	for _, scheme := range []mpc.Scheme{mpc.ECDSA, mpc.EdDSA} {
+		scheme := scheme
+		t.Run(string(scheme.Kind()), func(t *testing.T) {
+			t.Parallel()
+
+			ids := []string{"p1", "p2", "p3"}
+
+			setup, err := scheme.KeygenSetupMsgNew(2, nil, ids)
+			assert.NoError(t, err)
+
+			keygen := make([]mpc.Finisher[mpc.Keyshare], len(ids))
+			for i, id := range ids {
+				keygen[i], err = scheme.KeygenSessionFromSetup(setup, id)
+				assert.NoError(t, err)
+			}
+
+			shares, ok := runHolding(t, ids, keygen)
+			if !ok {
+				return
+			}
+
+			publicKey, err := shares[0].PublicKey()
+			assert.NoError(t, err)
+
+			for _, share := range shares {
+				pk, err := share.PublicKey()
+				assert.NoError(t, err)
+				assert.Equal(t, publicKey, pk)
+			}
+
+			keyID, err := shares[0].KeyID()
+			assert.NoError(t, err)
+
+			setup, err = scheme.SignSetupMsgNew(keyID, nil, msg, ids)
+			assert.NoError(t, err)
+
+			sign := make([]mpc.Finisher[[]byte], len(ids))
+			for i, id := range ids {
+				sign[i], err = scheme.SignSessionFromSetup(setup, id, shares[i])
+				assert.NoError(t, err)
+			}
+
+			signatures, ok := runHolding(t, ids, sign)
+			if ok {
+				assert.Equal(t, signatures[0], signatures[1])
+			}
+
+			for _, share := range shares {
+				assert.NoError(t, share.Close())
+			}
+		})
+	}
+}
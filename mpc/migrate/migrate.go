@@ -0,0 +1,506 @@
+// Package migrate converts legacy GG20 keyshares into DKLS and Schnorr keyshares.
+//
+// Vaults created with GG20 hold tss-lib keyshares: every party owns a Shamir
+// share x_i of the private key at its share ID k_i. The key migration sessions
+// of go-dkls and go-schnorr instead expect an additive share s_i_0 from every
+// participant, so that the shares of all participants sum up to the private key.
+// For a subset S of at least a threshold of parties, the additive share is the
+// Shamir share weighted with its Lagrange coefficient at zero:
+//
+//	s_i_0 = x_i · Π_{j∈S, j≠i} k_j / (k_j - k_i)  mod q
+//
+// Before a session is created, the public shares of the keyshare are used to
+// check that the additive shares of the subset add up to the public key, and the
+// migrated keyshare is checked against the public key when the session finishes.
+//
+// Key functionalities include:
+// - Reading legacy GG20 keyshares, the JSON LocalState of Vultisig vaults
+// - Computing the additive coefficient of a party for secp256k1 and Ed25519
+// - Creating and running key migration sessions for mpc.ECDSA and mpc.EdDSA
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"filippo.io/edwards25519"
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+
+	"github.com/vultisig/go-wrappers/mpc"
+)
+
+const (
+	curveSecp256k1 = "secp256k1"
+	curveEd25519   = "ed25519"
+)
+
+var (
+	// ErrPublicKey is returned when the shares of a keyshare do not match its public key.
+	ErrPublicKey = errors.New("migrate: shares do not match the public key")
+	// ErrParties is returned when the participants of a migration are invalid.
+	ErrParties = errors.New("migrate: invalid participants")
+)
+
+// ed25519Order is the order of the Ed25519 base point.
+var ed25519Order, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+
+// ecPoint is a curve point as serialized by tss-lib.
+type ecPoint struct {
+	Curve  string     `json:"Curve"`
+	Coords []*big.Int `json:"Coords"`
+}
+
+// localPartySaveData holds the fields of the tss-lib keygen output used by the
+// migration. ECDSA keyshares carry ECDSAPub, EdDSA keyshares EDDSAPub.
+type localPartySaveData struct {
+	Xi       *big.Int   `json:"Xi"`
+	ShareID  *big.Int   `json:"ShareID"`
+	Ks       []*big.Int `json:"Ks"`
+	BigXj    []*ecPoint `json:"BigXj"`
+	ECDSAPub *ecPoint   `json:"ECDSAPub"`
+	EDDSAPub *ecPoint   `json:"EDDSAPub"`
+}
+
+// localState is the keyshare format of GG20 Vultisig vaults.
+type localState struct {
+	PubKey              string             `json:"pub_key"`
+	ECDSALocalData      localPartySaveData `json:"ecdsa_local_data"`
+	EDDSALocalData      localPartySaveData `json:"eddsa_local_data"`
+	KeygenCommitteeKeys []string           `json:"keygen_committee_keys"`
+	LocalPartyKey       string             `json:"local_party_key"`
+	ChainCodeHex        string             `json:"chain_code_hex"`
+	ResharePrefix       string             `json:"reshare_prefix"`
+}
+
+// Keyshare is a legacy GG20 keyshare.
+type Keyshare struct {
+	// Kind is the scheme of the key.
+	Kind mpc.Kind
+	// PublicKey is the compressed secp256k1 or the Ed25519 public key.
+	PublicKey []byte
+	// ChainCode is the root chain code of the vault, passed on to the migrated keyshare.
+	ChainCode []byte
+	// LocalPartyKey is the ID of the party owning the keyshare.
+	LocalPartyKey string
+	// Committee lists the IDs of the parties which created the key.
+	Committee []string
+
+	xi      *big.Int
+	shareID *big.Int
+	// ks and publicShares hold the share ID and the public share x_j·G of every party.
+	ks           []*big.Int
+	publicShares [][]byte
+}
+
+// Parse reads a legacy GG20 keyshare, the JSON encoded LocalState of a vault.
+//
+// Parameters:
+//   - data: []byte - the JSON encoded keyshare.
+//
+// Returns:
+//   - *Keyshare: the keyshare.
+//   - error: an error if the keyshare can not be decoded or its public shares are invalid.
+func Parse(data []byte) (*Keyshare, error) {
+	var state localState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("migrate: decode keyshare: %w", err)
+	}
+
+	k := &Keyshare{
+		LocalPartyKey: state.LocalPartyKey,
+		Committee:     state.KeygenCommitteeKeys,
+	}
+
+	var (
+		saveData    localPartySaveData
+		publicPoint *ecPoint
+		curve       string
+	)
+
+	switch {
+	case state.ECDSALocalData.ECDSAPub != nil && state.EDDSALocalData.EDDSAPub == nil:
+		k.Kind, saveData, publicPoint, curve = mpc.KindECDSA, state.ECDSALocalData, state.ECDSALocalData.ECDSAPub, curveSecp256k1
+	case state.EDDSALocalData.EDDSAPub != nil && state.ECDSALocalData.ECDSAPub == nil:
+		k.Kind, saveData, publicPoint, curve = mpc.KindEdDSA, state.EDDSALocalData, state.EDDSALocalData.EDDSAPub, curveEd25519
+	default:
+		return nil, errors.New("migrate: keyshare must hold exactly one of an ECDSA and an EdDSA key")
+	}
+
+	if saveData.Xi == nil || saveData.ShareID == nil {
+		return nil, errors.New("migrate: keyshare has no secret share")
+	}
+
+	if len(saveData.Ks) == 0 || len(saveData.Ks) != len(saveData.BigXj) {
+		return nil, errors.New("migrate: keyshare has inconsistent share IDs and public shares")
+	}
+
+	var err error
+
+	if k.PublicKey, err = encodePoint(curve, publicPoint); err != nil {
+		return nil, err
+	}
+
+	if state.PubKey != "" && state.PubKey != hex.EncodeToString(k.PublicKey) {
+		return nil, fmt.Errorf("%w: pub_key %s", ErrPublicKey, state.PubKey)
+	}
+
+	if state.ChainCodeHex != "" {
+		if k.ChainCode, err = hex.DecodeString(state.ChainCodeHex); err != nil {
+			return nil, fmt.Errorf("migrate: chain code: %w", err)
+		}
+	}
+
+	k.xi, k.shareID, k.ks = saveData.Xi, saveData.ShareID, saveData.Ks
+	k.publicShares = make([][]byte, len(saveData.BigXj))
+
+	for j, point := range saveData.BigXj {
+		if saveData.Ks[j] == nil {
+			return nil, fmt.Errorf("migrate: share ID %d is missing", j)
+		}
+
+		if k.publicShares[j], err = encodePoint(curve, point); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := k.index(); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// encodePoint validates a tss-lib point and encodes it like the public keys of its scheme.
+func encodePoint(curve string, p *ecPoint) ([]byte, error) {
+	if p == nil || p.Curve != curve || len(p.Coords) != 2 || p.Coords[0] == nil || p.Coords[1] == nil {
+		return nil, fmt.Errorf("migrate: invalid %s point", curve)
+	}
+
+	x, y := p.Coords[0], p.Coords[1]
+
+	switch curve {
+	case curveSecp256k1:
+		if !secp256k1.S256().IsOnCurve(x, y) {
+			return nil, fmt.Errorf("migrate: %s point is not on the curve", curve)
+		}
+
+		return secp256k1.CompressPubkey(x, y), nil
+	default:
+		if y.Sign() < 0 || y.BitLen() > 255 || x.Sign() < 0 {
+			return nil, fmt.Errorf("migrate: invalid %s point", curve)
+		}
+
+		// The compressed form is y in little-endian order with the parity of x in the top bit.
+		buf := reverse(y.FillBytes(make([]byte, 32)))
+		buf[31] |= byte(x.Bit(0)) << 7
+
+		point, err := new(edwards25519.Point).SetBytes(buf)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %s point is not on the curve", curve)
+		}
+
+		return point.Bytes(), nil
+	}
+}
+
+// index returns the position of the local party in the share IDs.
+func (k *Keyshare) index() (int, error) {
+	for j, id := range k.ks {
+		if id.Cmp(k.shareID) == 0 {
+			return j, nil
+		}
+	}
+
+	return 0, errors.New("migrate: share ID of the keyshare is not among its share IDs")
+}
+
+// PartyIndex returns the position of a party among the share IDs of the keyshare.
+// tss-lib, as used by Vultisig, takes the bytes of the party ID as big-endian
+// integer for its share ID.
+//
+// Parameters:
+//   - id: string - the ID of the party, e.g. one of Committee.
+//
+// Returns:
+//   - int: the position of the party, to be passed in the participants of Coefficient.
+//   - error: an error if no share ID belongs to the party.
+func (k *Keyshare) PartyIndex(id string) (int, error) {
+	shareID := new(big.Int).SetBytes([]byte(id))
+
+	for j, ks := range k.ks {
+		if ks.Cmp(shareID) == 0 {
+			return j, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: no share of party %q", ErrParties, id)
+}
+
+func (k *Keyshare) order() *big.Int {
+	if k.Kind == mpc.KindECDSA {
+		return secp256k1.S256().N
+	}
+
+	return ed25519Order
+}
+
+// lagrange returns the Lagrange coefficient at zero of every participant.
+func (k *Keyshare) lagrange(participants []int) ([]*big.Int, error) {
+	q := k.order()
+	seen := make(map[int]bool, len(participants))
+
+	for _, j := range participants {
+		if j < 0 || j >= len(k.ks) || seen[j] {
+			return nil, fmt.Errorf("%w: position %d", ErrParties, j)
+		}
+
+		seen[j] = true
+	}
+
+	coefficients := make([]*big.Int, len(participants))
+
+	for i, pi := range participants {
+		num, den := big.NewInt(1), big.NewInt(1)
+
+		for _, pj := range participants {
+			if pj == pi {
+				continue
+			}
+
+			num.Mul(num, k.ks[pj]).Mod(num, q)
+			den.Mul(den, new(big.Int).Sub(k.ks[pj], k.ks[pi])).Mod(den, q)
+		}
+
+		if den.ModInverse(den, q) == nil {
+			return nil, fmt.Errorf("%w: duplicate share IDs", ErrParties)
+		}
+
+		coefficients[i] = num.Mul(num, den).Mod(num, q)
+	}
+
+	return coefficients, nil
+}
+
+// Coefficient computes the additive share of the local party, the secret
+// coefficient of a key migration session in which the given parties take part.
+//
+// It checks that the Shamir share matches the public share of the local party
+// and that the additive shares of the participants add up to the public key.
+//
+// Parameters:
+//   - participants: []int - the positions of the participating parties among the share IDs,
+//     including the local party and at least a threshold of parties, see PartyIndex.
+//
+// Returns:
+//   - []byte: the coefficient, a big-endian secp256k1 or a little-endian Ed25519 scalar.
+//   - error: an error if the participants are invalid or do not reconstruct the public key.
+func (k *Keyshare) Coefficient(participants []int) ([]byte, error) {
+	self, err := k.index()
+	if err != nil {
+		return nil, err
+	}
+
+	pos := -1
+
+	for i, j := range participants {
+		if j == self {
+			pos = i
+		}
+	}
+
+	if pos < 0 {
+		return nil, fmt.Errorf("%w: the local party does not take part", ErrParties)
+	}
+
+	coefficients, err := k.lagrange(participants)
+	if err != nil {
+		return nil, err
+	}
+
+	publicShare, err := k.scalarBaseMult(k.xi)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(publicShare, k.publicShares[self]) {
+		return nil, fmt.Errorf("%w: secret share does not match its public share", ErrPublicKey)
+	}
+
+	publicShares := make([][]byte, len(participants))
+	for i, j := range participants {
+		publicShares[i] = k.publicShares[j]
+	}
+
+	publicKey, err := k.combine(coefficients, publicShares)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fewer than a threshold of participants interpolate a different key.
+	if !bytes.Equal(publicKey, k.PublicKey) {
+		return nil, fmt.Errorf("%w: too few participants or corrupted public shares", ErrPublicKey)
+	}
+
+	coefficient := new(big.Int).Mul(coefficients[pos], k.xi)
+	coefficient.Mod(coefficient, k.order())
+
+	if k.Kind == mpc.KindECDSA {
+		return coefficient.FillBytes(make([]byte, 32)), nil
+	}
+
+	return reverse(coefficient.FillBytes(make([]byte, 32))), nil
+}
+
+// scalarBaseMult encodes s·G.
+func (k *Keyshare) scalarBaseMult(s *big.Int) ([]byte, error) {
+	s = new(big.Int).Mod(s, k.order())
+
+	if k.Kind == mpc.KindECDSA {
+		x, y := secp256k1.S256().ScalarBaseMult(s.FillBytes(make([]byte, 32)))
+
+		return secp256k1.CompressPubkey(x, y), nil
+	}
+
+	scalar, err := edwards25519.NewScalar().SetCanonicalBytes(reverse(s.FillBytes(make([]byte, 32))))
+	if err != nil {
+		return nil, err
+	}
+
+	return new(edwards25519.Point).ScalarBaseMult(scalar).Bytes(), nil
+}
+
+// combine encodes Σ c_j·P_j.
+func (k *Keyshare) combine(coefficients []*big.Int, points [][]byte) ([]byte, error) {
+	if k.Kind == mpc.KindECDSA {
+		curve := secp256k1.S256()
+		sumX, sumY := new(big.Int), new(big.Int)
+
+		for i, point := range points {
+			x, y := secp256k1.DecompressPubkey(point)
+			if x == nil {
+				return nil, fmt.Errorf("migrate: invalid public share")
+			}
+
+			x, y = curve.ScalarMult(x, y, coefficients[i].FillBytes(make([]byte, 32)))
+			if x == nil {
+				return nil, fmt.Errorf("migrate: invalid public share")
+			}
+
+			sumX, sumY = curve.Add(sumX, sumY, x, y)
+		}
+
+		return secp256k1.CompressPubkey(sumX, sumY), nil
+	}
+
+	sum := edwards25519.NewIdentityPoint()
+
+	for i, buf := range points {
+		point, err := new(edwards25519.Point).SetBytes(buf)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: invalid public share")
+		}
+
+		scalar, err := edwards25519.NewScalar().SetCanonicalBytes(reverse(coefficients[i].FillBytes(make([]byte, 32))))
+		if err != nil {
+			return nil, err
+		}
+
+		sum.Add(sum, new(edwards25519.Point).ScalarMult(scalar, point))
+	}
+
+	return sum.Bytes(), nil
+}
+
+// session checks the public key of the migrated keyshare.
+type session struct {
+	mpc.KeygenSession
+	publicKey []byte
+}
+
+func (s session) Finish() (mpc.Keyshare, error) {
+	share, err := s.KeygenSession.Finish()
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := share.PublicKey()
+	if err == nil && !bytes.Equal(publicKey, s.publicKey) {
+		err = fmt.Errorf("%w: migrated keyshare has public key %x", ErrPublicKey, publicKey)
+	}
+
+	if err != nil {
+		share.Close()
+
+		return nil, err
+	}
+
+	return share, nil
+}
+
+// SessionFromSetup creates the key migration session of the local party.
+//
+// The setup is a keygen setup message of the scheme of the keyshare; its ids
+// are the new IDs of the participants, in the order of participants.
+//
+// Parameters:
+//   - setup: []byte - the keygen setup message.
+//   - id: string - the ID of the local party in the setup.
+//   - k: *Keyshare - the legacy keyshare of the local party.
+//   - participants: []int - the positions of the participating parties among the share IDs.
+//
+// Returns:
+//   - mpc.KeygenSession: the session; its Finish fails if the migrated keyshare has another public key.
+//   - error: an error if the coefficient can not be computed or the session can not be created.
+func SessionFromSetup(setup []byte, id string, k *Keyshare, participants []int) (mpc.KeygenSession, error) {
+	scheme, err := mpc.ByKind(k.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	coefficient, err := k.Coefficient(participants)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := scheme.KeyMigrateSessionFromSetup(setup, id, k.PublicKey, k.ChainCode, coefficient)
+	if err != nil {
+		return nil, err
+	}
+
+	return session{KeygenSession: s, publicKey: k.PublicKey}, nil
+}
+
+// Migrate runs the key migration of the local party end to end.
+//
+// Parameters:
+//   - ctx: context.Context - cancels the run.
+//   - d: *mpc.Driver - the driver of the local party.
+//   - setup: []byte - the keygen setup message.
+//   - k: *Keyshare - the legacy keyshare of the local party.
+//   - participants: []int - the positions of the participating parties among the share IDs.
+//
+// Returns:
+//   - mpc.Keyshare: the migrated keyshare; the caller must Close it.
+//   - error: an error if the migration fails or produces another public key.
+func Migrate(ctx context.Context, d *mpc.Driver, setup []byte, k *Keyshare, participants []int) (mpc.Keyshare, error) {
+	s, err := SessionFromSetup(setup, d.ID, k, participants)
+	if err != nil {
+		return nil, err
+	}
+
+	return mpc.Drive[mpc.Keyshare](ctx, d, s)
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+
+	return r
+}
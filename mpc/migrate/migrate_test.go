@@ -0,0 +1,224 @@
+package migrate_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+
+	"github.com/vultisig/go-wrappers/mpc"
+	"github.com/vultisig/go-wrappers/mpc/migrate"
+	"github.com/vultisig/go-wrappers/mpc/router"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// committee lists the parties of the GG20 vaults in testdata, 2-of-3 keys in
+// the LocalState JSON of Vultisig with one file per curve and party. tss-lib
+// sorts the share IDs, so the position of a party differs from its committee order.
+var committee = []string{"MacBook-A1B2", "iPhone-5C9", "Server-1234"}
+
+// fixture reads the GG20 keyshare of a party in testdata.
+func fixture(t *testing.T, kind mpc.Kind, id string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", fmt.Sprintf("%s-%s.json", kind, id)))
+	assert.NoError(t, err)
+
+	return data
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	chainCode, _ := hex.DecodeString("f61a6d0b9339f5e42d1fee8f5e9a7406bd50f7afc1234bb69f949a03d6302c79")
+
+	testCases := []struct {
+		kind      mpc.Kind
+		publicKey string
+	}{
+		{kind: mpc.KindECDSA, publicKey: "03690b14fcd5c810f0d10b8f2079e1bc1eff0716b29f9f03cbe77275663c293a45"},
+		// The x coordinate of the key is odd, so the top bit of the encoding is set.
+		{kind: mpc.KindEdDSA, publicKey: "4636023c870ec7137e9203efc5ab3b519cd7adc2a3e1fb1aa049ff98076a4fb5"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(string(tc.kind), func(t *testing.T) {
+			t.Parallel()
+
+			for _, id := range committee {
+				k, err := migrate.Parse(fixture(t, tc.kind, id))
+				assert.NoError(t, err)
+				assert.Equal(t, tc.kind, k.Kind)
+				assert.Equal(t, tc.publicKey, hex.EncodeToString(k.PublicKey))
+				assert.Equal(t, chainCode, k.ChainCode)
+				assert.Equal(t, id, k.LocalPartyKey)
+				assert.Equal(t, committee, k.Committee)
+
+				// The share IDs are the party IDs as big-endian integers, in ascending order.
+				for id, index := range map[string]int{"iPhone-5C9": 0, "Server-1234": 1, "MacBook-A1B2": 2} {
+					j, err := k.PartyIndex(id)
+					assert.NoError(t, err)
+					assert.Equal(t, index, j)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		kind      mpc.Kind
+		threshold int
+		// participants are the IDs of the migrating parties, in setup order.
+		participants []string
+	}{
+		{name: "ecdsa 2 of 3 with p1 and p3", kind: mpc.KindECDSA, threshold: 2, participants: []string{committee[0], committee[2]}},
+		{name: "ecdsa 2 of 3 with all parties", kind: mpc.KindECDSA, threshold: 2, participants: committee},
+		{name: "eddsa 2 of 3 with p3 and p2", kind: mpc.KindEdDSA, threshold: 2, participants: []string{committee[2], committee[1]}},
+		{name: "eddsa 2 of 3 with all parties", kind: mpc.KindEdDSA, threshold: 2, participants: committee},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			legacy := map[string]*migrate.Keyshare{}
+
+			for _, id := range committee {
+				k, err := migrate.Parse(fixture(t, tc.kind, id))
+				assert.NoError(t, err)
+
+				legacy[id] = k
+			}
+
+			participants := make([]int, len(tc.participants))
+			for i, id := range tc.participants {
+				index, err := legacy[id].PartyIndex(id)
+				assert.NoError(t, err)
+
+				participants[i] = index
+			}
+
+			scheme, err := mpc.ByKind(tc.kind)
+			assert.NoError(t, err)
+
+			setup, err := scheme.KeygenSetupMsgNew(tc.threshold, nil, tc.participants)
+			assert.NoError(t, err)
+
+			r := router.New(tc.participants)
+			results := make(chan error, len(tc.participants))
+			shares := make([]mpc.Keyshare, len(tc.participants))
+
+			for i, id := range tc.participants {
+				go func(i int, id string) {
+					d := &mpc.Driver{ID: id, Parties: tc.participants, Transport: r.Transport(id), Timeout: time.Minute}
+
+					var err error
+					shares[i], err = migrate.Migrate(context.Background(), d, setup, legacy[id], participants)
+					results <- err
+				}(i, id)
+			}
+
+			for range tc.participants {
+				assert.NoError(t, <-results)
+			}
+
+			publicKey := legacy[committee[0]].PublicKey
+
+			for _, share := range shares {
+				pk, err := share.PublicKey()
+				assert.NoError(t, err)
+				assert.Equal(t, publicKey, pk)
+			}
+
+			msg := sha256.Sum256([]byte("migrated key signs"))
+
+			keyID, err := shares[0].KeyID()
+			assert.NoError(t, err)
+
+			setup, err = scheme.SignSetupMsgNew(keyID, nil, msg[:], tc.participants[:tc.threshold])
+			assert.NoError(t, err)
+
+			sessions := map[string]mpc.Finisher[[]byte]{}
+			for i, id := range tc.participants[:tc.threshold] {
+				sessions[id], err = scheme.SignSessionFromSetup(setup, id, shares[i])
+				assert.NoError(t, err)
+			}
+
+			signatures, err := router.Run(context.Background(), sessions, time.Minute)
+			assert.NoError(t, err)
+
+			for _, signature := range signatures {
+				if tc.kind == mpc.KindECDSA {
+					assert.True(t, secp256k1.VerifySignature(publicKey, msg[:], signature[:64]))
+				} else {
+					assert.True(t, ed25519.Verify(publicKey, msg[:], signature))
+				}
+			}
+
+			for _, share := range shares {
+				share.Close()
+			}
+		})
+	}
+}
+
+func TestCoefficientErrors(t *testing.T) {
+	t.Parallel()
+
+	data := fixture(t, mpc.KindECDSA, "iPhone-5C9")
+
+	k, err := migrate.Parse(data)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		participants []int
+		err          error
+	}{
+		{name: "below threshold", participants: []int{0}, err: migrate.ErrPublicKey},
+		{name: "local party missing", participants: []int{1, 2}, err: migrate.ErrParties},
+		{name: "duplicate party", participants: []int{0, 0}, err: migrate.ErrParties},
+		{name: "out of range", participants: []int{0, 3}, err: migrate.ErrParties},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := k.Coefficient(tc.participants)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	_, err = k.PartyIndex("p4")
+	assert.ErrorIs(t, err, migrate.ErrParties)
+
+	var state map[string]any
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	assert.NoError(t, decoder.Decode(&state))
+
+	state["pub_key"] = "02aa"
+
+	tampered, err := json.Marshal(state)
+	assert.NoError(t, err)
+
+	_, err = migrate.Parse(tampered)
+	assert.ErrorIs(t, err, migrate.ErrPublicKey)
+}
@@ -0,0 +1,504 @@
+// Package presign keeps a pool of DKLS pre-signatures ready for low-latency signing.
+//
+// A Pool runs pre-sign sessions in the background until every key has the
+// target number of pre-signatures, persists the serialized pre-signatures on
+// disk and hands out one per signing request together with the finish setup
+// message built from its session ID.
+//
+// Reusing a pre-signature for two different messages leaks the private key.
+// Before a pre-signature is handed out, its session ID and the message hash
+// are appended to a ledger and synced to disk; a session ID found in the
+// ledger is never handed out again, not even after a crash.
+//
+// Key functionalities include:
+// - Background generation of pre-signatures up to a target depth per key ID
+// - Durable storage of serialized pre-signatures
+// - A durable single-use ledger
+// - Building finish setup messages
+package presign
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	session "github.com/vultisig/go-wrappers/go-dkls/sessions"
+)
+
+const (
+	ledgerFile = "ledger"
+	presignExt = ".presign"
+)
+
+var (
+	// ErrEmpty is returned when no pre-signature is available for a key.
+	ErrEmpty = errors.New("presign: pool is empty")
+	// ErrUsed is returned when a pre-signature has already been handed out.
+	ErrUsed = errors.New("presign: pre-signature already used")
+)
+
+// Producer runs one pre-sign session for a key and returns the serialized
+// pre-signatures of the local parties, keyed by party ID. All of them must
+// belong to the same session. A device returns its own pre-signature only;
+// a server running all co-signers in one process returns all of them.
+type Producer func(ctx context.Context, keyID []byte) (map[string][]byte, error)
+
+// Config configures a Pool.
+type Config struct {
+	// Dir is the directory holding the pre-signatures and the ledger.
+	Dir string
+	// Depth is the target number of pre-signatures per key ID.
+	Depth int
+	// Producer runs the pre-sign sessions.
+	Producer Producer
+	// RetryDelay is the delay after a failed pre-sign session. Zero means one second.
+	RetryDelay time.Duration
+}
+
+// Lease is a pre-signature handed out for exactly one message.
+type Lease struct {
+	// KeyID is the ID of the key.
+	KeyID []byte
+	// SessionID is the session ID of the pre-signature.
+	SessionID []byte
+	// MessageHash is the hash of the message the pre-signature is bound to.
+	MessageHash []byte
+	// Presigns holds the serialized pre-signatures of the local parties, keyed by party ID.
+	Presigns map[string][]byte
+}
+
+// FinishSetup builds the finish setup message of the lease.
+//
+// Parameters:
+//   - ids: []string - the IDs of the signers, in the order used for pre-signing.
+//
+// Returns:
+//   - []byte: the finish setup message.
+//   - error: an error if the setup message can not be created.
+func (l *Lease) FinishSetup(ids []string) ([]byte, error) {
+	return session.DklsFinishSetupMsgNew(l.SessionID, l.MessageHash, []byte(strings.Join(ids, "\x00")))
+}
+
+// Presign deserializes the pre-signature of a local party.
+//
+// Parameters:
+//   - partyID: string - the ID of the party.
+//
+// Returns:
+//   - *session.Presign: the pre-signature; the caller must Close it.
+//   - error: an error if the party has no pre-signature in the lease.
+func (l *Lease) Presign(partyID string) (*session.Presign, error) {
+	buf, ok := l.Presigns[partyID]
+	if !ok {
+		return nil, fmt.Errorf("presign: no pre-signature of %s", partyID)
+	}
+
+	return session.PresignFromBytes(buf)
+}
+
+// Pool is a durable pool of pre-signatures. It is safe for concurrent use.
+type Pool struct {
+	cfg Config
+
+	mu     sync.Mutex
+	ledger *os.File
+	used   map[string]bool
+	// ready lists the hex session IDs of the available pre-signatures per hex key ID, oldest first.
+	ready map[string][]string
+	wake  chan struct{}
+}
+
+// Open opens the pool in cfg.Dir, creating it if needed. Pre-signatures found
+// in the ledger are deleted.
+//
+// Parameters:
+//   - cfg: Config - the configuration of the pool.
+//
+// Returns:
+//   - *Pool: the pool; the caller must Close it.
+//   - error: an error if the directory or the ledger can not be read.
+func Open(cfg Config) (*Pool, error) {
+	if cfg.Depth <= 0 {
+		return nil, errors.New("presign: depth must be positive")
+	}
+
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = time.Second
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	p := &Pool{
+		cfg:   cfg,
+		used:  make(map[string]bool),
+		ready: make(map[string][]string),
+		wake:  make(chan struct{}, 1),
+	}
+
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+
+	ledger, err := os.OpenFile(filepath.Join(cfg.Dir, ledgerFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ledger = ledger
+
+	return p, nil
+}
+
+// load reads the ledger and the stored pre-signatures.
+func (p *Pool) load() error {
+	f, err := os.Open(filepath.Join(p.cfg.Dir, ledgerFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if f != nil {
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+				p.used[fields[0]] = true
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	keys, err := os.ReadDir(p.cfg.Dir)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if !key.IsDir() {
+			continue
+		}
+
+		entries, err := os.ReadDir(filepath.Join(p.cfg.Dir, key.Name()))
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			sessionID, ok := strings.CutSuffix(entry.Name(), presignExt)
+			if !ok {
+				continue
+			}
+
+			if p.used[sessionID] {
+				// Handed out before a crash, but not yet deleted.
+				if err := os.Remove(filepath.Join(p.cfg.Dir, key.Name(), entry.Name())); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			p.ready[key.Name()] = append(p.ready[key.Name()], sessionID)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the ledger.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.ledger.Close()
+}
+
+// Available returns the number of pre-signatures available for a key.
+func (p *Pool) Available(keyID []byte) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.ready[hex.EncodeToString(keyID)])
+}
+
+// Fill runs pre-sign sessions until the key has cfg.Depth pre-signatures.
+//
+// Parameters:
+//   - ctx: context.Context - cancels the filling.
+//   - keyID: []byte - the ID of the key.
+//
+// Returns:
+//   - error: the first error of the producer or the storage.
+func (p *Pool) Fill(ctx context.Context, keyID []byte) error {
+	for p.Available(keyID) < p.cfg.Depth {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		presigns, err := p.cfg.Producer(ctx, keyID)
+		if err != nil {
+			return fmt.Errorf("presign: produce: %w", err)
+		}
+
+		if err := p.Add(keyID, presigns); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run keeps every key filled to cfg.Depth until the context is done. It refills
+// a key as soon as a pre-signature is handed out and retries failed sessions
+// after cfg.RetryDelay.
+//
+// Parameters:
+//   - ctx: context.Context - stops the pool.
+//   - keyIDs: [][]byte - the IDs of the keys to keep filled.
+//
+// Returns:
+//   - error: the error of the context.
+func (p *Pool) Run(ctx context.Context, keyIDs [][]byte) error {
+	for {
+		failed := false
+
+		for _, keyID := range keyIDs {
+			if err := p.Fill(ctx, keyID); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+
+				failed = true
+			}
+		}
+
+		var retry <-chan time.Time
+		if failed {
+			retry = time.After(p.cfg.RetryDelay)
+		}
+
+		select {
+		case <-p.wake:
+		case <-retry:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Add stores the pre-signatures of one pre-sign session.
+//
+// Parameters:
+//   - keyID: []byte - the ID of the key.
+//   - presigns: map[string][]byte - the serialized pre-signatures keyed by party ID.
+//
+// Returns:
+//   - error: an error if the pre-signatures are invalid, used or can not be stored.
+func (p *Pool) Add(keyID []byte, presigns map[string][]byte) error {
+	var sessionID string
+
+	for partyID, buf := range presigns {
+		presign, err := session.PresignFromBytes(buf)
+		if err != nil {
+			return fmt.Errorf("presign: pre-signature of %s: %w", partyID, err)
+		}
+
+		id, err := presign.SessionID()
+		presign.Close()
+
+		if err != nil {
+			return err
+		}
+
+		if sessionID != "" && sessionID != hex.EncodeToString(id) {
+			return errors.New("presign: pre-signatures of different sessions")
+		}
+
+		sessionID = hex.EncodeToString(id)
+	}
+
+	if sessionID == "" {
+		return errors.New("presign: no pre-signatures")
+	}
+
+	data, err := json.Marshal(presigns)
+	if err != nil {
+		return err
+	}
+
+	key := hex.EncodeToString(keyID)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.used[sessionID] {
+		return fmt.Errorf("%w: session %s", ErrUsed, sessionID)
+	}
+
+	if err := writeFile(filepath.Join(p.cfg.Dir, key), sessionID+presignExt, data); err != nil {
+		return err
+	}
+
+	p.ready[key] = append(p.ready[key], sessionID)
+
+	return nil
+}
+
+// Acquire hands out the oldest pre-signature of a key for a message. It is
+// used by the party initiating a signature; the other parties acquire the
+// same session with AcquireSession.
+//
+// Parameters:
+//   - keyID: []byte - the ID of the key.
+//   - messageHash: []byte - the hash of the message to sign.
+//
+// Returns:
+//   - *Lease: the pre-signature.
+//   - error: ErrEmpty if no pre-signature is available.
+func (p *Pool) Acquire(keyID []byte, messageHash []byte) (*Lease, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ready := p.ready[hex.EncodeToString(keyID)]
+	if len(ready) == 0 {
+		return nil, ErrEmpty
+	}
+
+	return p.take(keyID, ready[0], messageHash)
+}
+
+// AcquireSession hands out the pre-signature of a given session for a message.
+//
+// Parameters:
+//   - keyID: []byte - the ID of the key.
+//   - sessionID: []byte - the session ID of the pre-signature.
+//   - messageHash: []byte - the hash of the message to sign.
+//
+// Returns:
+//   - *Lease: the pre-signature.
+//   - error: ErrUsed if the pre-signature has been handed out, ErrEmpty if it is unknown.
+func (p *Pool) AcquireSession(keyID []byte, sessionID []byte, messageHash []byte) (*Lease, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := hex.EncodeToString(sessionID)
+	if p.used[id] {
+		return nil, fmt.Errorf("%w: session %s", ErrUsed, id)
+	}
+
+	for _, ready := range p.ready[hex.EncodeToString(keyID)] {
+		if ready == id {
+			return p.take(keyID, id, messageHash)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: no session %s", ErrEmpty, id)
+}
+
+// take records a pre-signature in the ledger and removes it from the pool. p.mu must be held.
+func (p *Pool) take(keyID []byte, sessionID string, messageHash []byte) (*Lease, error) {
+	key := hex.EncodeToString(keyID)
+	path := filepath.Join(p.cfg.Dir, key, sessionID+presignExt)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var presigns map[string][]byte
+	if err := json.Unmarshal(data, &presigns); err != nil {
+		return nil, err
+	}
+
+	// The ledger entry must be durable before the pre-signature leaves the pool.
+	if _, err := fmt.Fprintf(p.ledger, "%s %s\n", sessionID, hex.EncodeToString(messageHash)); err != nil {
+		return nil, err
+	}
+
+	if err := p.ledger.Sync(); err != nil {
+		return nil, err
+	}
+
+	p.used[sessionID] = true
+
+	ready := p.ready[key]
+	for i, id := range ready {
+		if id == sessionID {
+			p.ready[key] = append(ready[:i:i], ready[i+1:]...)
+			break
+		}
+	}
+
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+
+	sid, err := hex.DecodeString(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Lease{KeyID: keyID, SessionID: sid, MessageHash: messageHash, Presigns: presigns}, nil
+}
+
+// writeFile writes a file atomically and durably.
+func writeFile(dir string, name string, data []byte) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, name+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir makes renames in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
@@ -0,0 +1,145 @@
+package presign_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+	"testing"
+	"time"
+
+	session "github.com/vultisig/go-wrappers/go-dkls/sessions"
+	"github.com/vultisig/go-wrappers/mpc"
+	"github.com/vultisig/go-wrappers/mpc/presign"
+	"github.com/vultisig/go-wrappers/mpc/router"
+
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+	"github.com/stretchr/testify/assert"
+)
+
+var ids = []string{"p1", "p2"}
+
+func keygen(t *testing.T) map[string]*session.Keyshare {
+	setup, err := mpc.ECDSA.KeygenSetupMsgNew(2, nil, ids)
+	assert.NoError(t, err)
+
+	sessions := make(map[string]mpc.Finisher[mpc.Keyshare], len(ids))
+	for _, id := range ids {
+		sessions[id], err = mpc.ECDSA.KeygenSessionFromSetup(setup, id)
+		assert.NoError(t, err)
+	}
+
+	shares, err := router.Run(context.Background(), sessions, time.Minute)
+	assert.NoError(t, err)
+
+	dkls := make(map[string]*session.Keyshare, len(ids))
+	for id, share := range shares {
+		dkls[id], err = mpc.ECDSAKeyshare(share)
+		assert.NoError(t, err)
+	}
+
+	return dkls
+}
+
+// producer runs a pre-sign session of all co-signers in one process.
+func producer(shares map[string]*session.Keyshare) presign.Producer {
+	return func(ctx context.Context, keyID []byte) (map[string][]byte, error) {
+		setup, err := session.DklsSignSetupMsgNew(keyID, nil, nil, []byte(strings.Join(ids, "\x00")))
+		if err != nil {
+			return nil, err
+		}
+
+		sessions := make(map[string]mpc.Finisher[[]byte], len(ids))
+		for _, id := range ids {
+			if sessions[id], err = session.NewSignSession(setup, []byte(id), shares[id]); err != nil {
+				return nil, err
+			}
+		}
+
+		return router.Run(ctx, sessions, time.Minute)
+	}
+}
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+
+	shares := keygen(t)
+
+	keyID, err := shares["p1"].KeyID()
+	assert.NoError(t, err)
+
+	publicKey, err := shares["p1"].PublicKey()
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+
+	pool, err := presign.Open(presign.Config{Dir: dir, Depth: 2, Producer: producer(shares)})
+	assert.NoError(t, err)
+
+	assert.NoError(t, pool.Fill(context.Background(), keyID))
+	assert.Equal(t, 2, pool.Available(keyID))
+
+	hash := sha256.Sum256([]byte("pay 1 BTC"))
+
+	lease, err := pool.Acquire(keyID, hash[:])
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pool.Available(keyID))
+
+	setup, err := lease.FinishSetup(ids)
+	assert.NoError(t, err)
+
+	sessions := make(map[string]mpc.Finisher[[]byte], len(ids))
+	for _, id := range ids {
+		p, err := lease.Presign(id)
+		assert.NoError(t, err)
+
+		sessions[id], err = session.NewFinishSession(setup, []byte(id), p)
+		assert.NoError(t, err)
+		assert.NoError(t, p.Close())
+	}
+
+	signatures, err := router.Run(context.Background(), sessions, time.Minute)
+	assert.NoError(t, err)
+
+	for _, signature := range signatures {
+		assert.True(t, secp256k1.VerifySignature(publicKey, hash[:], signature[:64]))
+	}
+
+	// The pre-signature is gone for good, also after reopening the pool.
+	other := sha256.Sum256([]byte("pay 2 BTC"))
+
+	_, err = pool.AcquireSession(keyID, lease.SessionID, other[:])
+	assert.ErrorIs(t, err, presign.ErrUsed)
+	assert.NoError(t, pool.Close())
+
+	pool, err = presign.Open(presign.Config{Dir: dir, Depth: 2, Producer: producer(shares)})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pool.Available(keyID))
+
+	_, err = pool.AcquireSession(keyID, lease.SessionID, other[:])
+	assert.ErrorIs(t, err, presign.ErrUsed)
+
+	err = pool.Add(keyID, lease.Presigns)
+	assert.ErrorIs(t, err, presign.ErrUsed)
+
+	// Run refills the pool in the background.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() {
+		done <- pool.Run(ctx, [][]byte{keyID})
+	}()
+
+	assert.Eventually(t, func() bool { return pool.Available(keyID) == 2 }, time.Minute, 10*time.Millisecond)
+
+	_, err = pool.Acquire(keyID, other[:])
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return pool.Available(keyID) == 2 }, time.Minute, 10*time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.NoError(t, pool.Close())
+
+	_, err = presign.Open(presign.Config{Dir: dir})
+	assert.Error(t, err)
+}
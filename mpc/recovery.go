@@ -0,0 +1,61 @@
+package mpc
+
+import (
+	"context"
+)
+
+// Recover regenerates the full keyshare of the local party from its refresh
+// share backup, as produced by Scheme.KeyshareToRefreshBytes.
+//
+// The setup is a keygen setup message created with KeygenSetupMsgNew for the
+// key ID of the recovered key. Its ids list the recovering party and its live
+// co-signers, a threshold of parties in total; each co-signer runs Refresh with
+// its full keyshare on the same setup. All participants end up with fresh
+// keyshares of the same public key for a quorum of the listed parties only, so
+// every reachable co-signer should take part: the old shares of parties left
+// out no longer combine with the new ones. With fewer than all parties of the
+// quorum, go-dkls only accepts the parties holding the lowest party indices,
+// e.g. p1 and p2 but not p1 and p3 of a 2-of-3 key; any other subset returns
+// ErrUnsupported before the session starts. go-schnorr has no refresh shares,
+// so EdDSA keys return ErrUnsupported.
+//
+// Parameters:
+//   - ctx: context.Context - cancels the run.
+//   - d: *Driver - the driver of the recovering party.
+//   - scheme: Scheme - the scheme of the key.
+//   - setup: []byte - the keygen setup message.
+//   - refreshShare: []byte - the refresh share backup of the recovering party.
+//
+// Returns:
+//   - Keyshare: the recovered keyshare; the caller must Close it.
+//   - error: an error if the refresh share is invalid or the session fails.
+func Recover(ctx context.Context, d *Driver, scheme Scheme, setup []byte, refreshShare []byte) (Keyshare, error) {
+	session, err := scheme.KeyRecoverySessionFromSetup(setup, d.ID, refreshShare)
+	if err != nil {
+		return nil, err
+	}
+
+	return Drive[Keyshare](ctx, d, session)
+}
+
+// Refresh runs a key refresh of the local party, e.g. as a co-signer of a
+// recovery started with Recover.
+//
+// Parameters:
+//   - ctx: context.Context - cancels the run.
+//   - d: *Driver - the driver of the local party.
+//   - scheme: Scheme - the scheme of the key.
+//   - setup: []byte - the keygen setup message carrying the key ID of the key.
+//   - keyshare: Keyshare - the current keyshare of the local party; it is left open.
+//
+// Returns:
+//   - Keyshare: the refreshed keyshare; the caller must Close it.
+//   - error: an error if the setup lists parties Recover does not support or the session fails.
+func Refresh(ctx context.Context, d *Driver, scheme Scheme, setup []byte, keyshare Keyshare) (Keyshare, error) {
+	session, err := scheme.KeyRefreshSessionFromSetup(setup, d.ID, keyshare)
+	if err != nil {
+		return nil, err
+	}
+
+	return Drive[Keyshare](ctx, d, session)
+}
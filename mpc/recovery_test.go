@@ -0,0 +1,194 @@
+package mpc_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vultisig/go-wrappers/mpc"
+
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// live are the co-signers joining the recovery of p1.
+		live []string
+	}{
+		{name: "recover with all co-signers", live: []string{"p2", "p3"}},
+		{name: "recover with a threshold of co-signers", live: []string{"p2"}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			shares := runKeygen(t, mpc.ECDSA, 2, 3)
+			defer func() {
+				for _, share := range shares {
+					assert.NoError(t, share.Close())
+				}
+			}()
+
+			publicKey, err := shares[0].PublicKey()
+			assert.NoError(t, err)
+
+			keyID, err := shares[0].KeyID()
+			assert.NoError(t, err)
+
+			backup, err := mpc.ECDSA.KeyshareToRefreshBytes(shares[0])
+			assert.NoError(t, err)
+
+			ids := append([]string{"p1"}, tc.live...)
+			setup, err := mpc.ECDSA.KeygenSetupMsgNew(2, keyID, ids)
+			assert.NoError(t, err)
+
+			transports := newChanTransports(ids)
+			recovered := make([]mpc.Keyshare, len(ids))
+
+			var wg sync.WaitGroup
+			for i, id := range ids {
+				wg.Add(1)
+
+				go func(i int, id string) {
+					defer wg.Done()
+
+					d := &mpc.Driver{ID: id, Parties: ids, Transport: transports[id], Timeout: time.Minute}
+
+					var err error
+					if i == 0 {
+						recovered[i], err = mpc.Recover(context.Background(), d, mpc.ECDSA, setup, backup)
+					} else {
+						recovered[i], err = mpc.Refresh(context.Background(), d, mpc.ECDSA, setup, shares[i])
+					}
+					assert.NoError(t, err)
+				}(i, id)
+			}
+
+			wg.Wait()
+
+			for _, share := range recovered {
+				pk, err := share.PublicKey()
+				assert.NoError(t, err)
+				assert.Equal(t, publicKey, pk)
+			}
+
+			msg := make([]byte, 32)
+			copy(msg, "recovered share signs")
+
+			for _, signature := range runSign(t, mpc.ECDSA, recovered[:2], msg) {
+				assert.True(t, secp256k1.VerifySignature(publicKey, msg, signature[:64]))
+			}
+
+			for _, share := range recovered {
+				assert.NoError(t, share.Close())
+			}
+		})
+	}
+}
+
+func TestRecoverSubsets(t *testing.T) {
+	t.Parallel()
+
+	shares := runKeygen(t, mpc.ECDSA, 2, 3)
+	t.Cleanup(func() {
+		for _, share := range shares {
+			assert.NoError(t, share.Close())
+		}
+	})
+
+	keyID, err := shares[0].KeyID()
+	assert.NoError(t, err)
+
+	index := map[string]int{"p1": 0, "p2": 1, "p3": 2}
+
+	// The first party recovers from its backup, the others refresh their keyshares.
+	// go-dkls only refreshes p1 and p2 of a 2-of-3 key with two parties.
+	testCases := []struct {
+		name     string
+		ids      []string
+		rejected []string
+	}{
+		{name: "all parties", ids: []string{"p3", "p1", "p2"}},
+		{name: "lowest indices", ids: []string{"p1", "p2"}},
+		{name: "lowest indices reversed", ids: []string{"p2", "p1"}},
+		{name: "first and last", ids: []string{"p1", "p3"}, rejected: []string{"p3"}},
+		{name: "last recovers", ids: []string{"p3", "p1"}, rejected: []string{"p3"}},
+		{name: "highest indices", ids: []string{"p2", "p3"}, rejected: []string{"p3"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			setup, err := mpc.ECDSA.KeygenSetupMsgNew(2, keyID, tc.ids)
+			assert.NoError(t, err)
+
+			for i, id := range tc.ids {
+				share := shares[index[id]]
+
+				var session mpc.KeygenSession
+				if i == 0 {
+					var backup []byte
+					backup, err = mpc.ECDSA.KeyshareToRefreshBytes(share)
+					assert.NoError(t, err)
+
+					session, err = mpc.ECDSA.KeyRecoverySessionFromSetup(setup, id, backup)
+				} else {
+					session, err = mpc.ECDSA.KeyRefreshSessionFromSetup(setup, id, share)
+				}
+
+				if slices.Contains(tc.rejected, id) {
+					assert.ErrorIs(t, err, mpc.ErrUnsupported, id)
+					continue
+				}
+
+				if assert.NoError(t, err, id) {
+					assert.NoError(t, session.Close())
+				}
+			}
+		})
+	}
+
+	// The drivers reject the party before any round runs.
+	ids := []string{"p1", "p3"}
+	setup, err := mpc.ECDSA.KeygenSetupMsgNew(2, keyID, ids)
+	assert.NoError(t, err)
+
+	d := &mpc.Driver{ID: "p3", Parties: ids, Transport: newChanTransports(ids)["p3"], Timeout: time.Minute}
+
+	_, err = mpc.Refresh(context.Background(), d, mpc.ECDSA, setup, shares[2])
+	assert.ErrorIs(t, err, mpc.ErrUnsupported)
+
+	backup, err := mpc.ECDSA.KeyshareToRefreshBytes(shares[2])
+	assert.NoError(t, err)
+
+	ids = []string{"p3", "p1"}
+	setup, err = mpc.ECDSA.KeygenSetupMsgNew(2, keyID, ids)
+	assert.NoError(t, err)
+
+	d = &mpc.Driver{ID: "p3", Parties: ids, Transport: newChanTransports(ids)["p3"], Timeout: time.Minute}
+
+	_, err = mpc.Recover(context.Background(), d, mpc.ECDSA, setup, backup)
+	assert.ErrorIs(t, err, mpc.ErrUnsupported)
+}
+
+func TestRecoverUnsupported(t *testing.T) {
+	t.Parallel()
+
+	shares := runKeygen(t, mpc.EdDSA, 2, 2)
+
+	_, err := mpc.EdDSA.KeyshareToRefreshBytes(shares[0])
+	assert.ErrorIs(t, err, mpc.ErrUnsupported)
+
+	_, err = mpc.EdDSA.KeyRecoverySessionFromSetup(nil, "p1", nil)
+	assert.ErrorIs(t, err, mpc.ErrUnsupported)
+}
@@ -0,0 +1,176 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultPollWait is the long-poll wait used by clients which do not set one.
+const DefaultPollWait = 10 * time.Second
+
+// Client is the transport of one party of one session through a relay server.
+// It implements mpc.Transport.
+type Client struct {
+	// BaseURL is the URL of the relay server.
+	BaseURL string
+	// SessionID is the ID of the session, usually SessionID(setup).
+	SessionID string
+	// PartyID is the ID of the local party.
+	PartyID string
+	// HTTPClient sends the requests. Nil means http.DefaultClient.
+	HTTPClient *http.Client
+	// PollWait is how long the server may hold a poll open. Zero means DefaultPollWait.
+	PollWait time.Duration
+
+	mu      sync.Mutex
+	pending []Message
+	// after is the sequence number of the last message received from the server.
+	after uint64
+}
+
+// NewClient creates a client transport.
+//
+// Parameters:
+//   - baseURL: string - the URL of the relay server.
+//   - sessionID: string - the ID of the session.
+//   - partyID: string - the ID of the local party.
+//
+// Returns:
+//   - *Client: the client.
+func NewClient(baseURL string, sessionID string, partyID string) *Client {
+	return &Client{BaseURL: baseURL, SessionID: sessionID, PartyID: partyID}
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+
+	return c.HTTPClient
+}
+
+func (c *Client) messagesURL() string {
+	return c.BaseURL + "/sessions/" + url.PathEscape(c.SessionID) + "/messages"
+}
+
+// Send posts a message to the mailbox of the receiver.
+func (c *Client) Send(ctx context.Context, receiver string, message []byte) error {
+	body, err := json.Marshal(Message{From: c.PartyID, To: receiver, Body: message})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.messagesURL(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkStatus(resp, http.StatusNoContent)
+}
+
+// Receive returns the next message of the local party, long-polling the relay
+// server until one arrives or the context is done.
+func (c *Client) Receive(ctx context.Context) (string, []byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.pending) == 0 {
+		messages, err := c.poll(ctx)
+		if err != nil {
+			return "", nil, err
+		}
+
+		c.pending = messages
+	}
+
+	msg := c.pending[0]
+	c.pending = c.pending[1:]
+
+	return msg.From, msg.Body, nil
+}
+
+func (c *Client) poll(ctx context.Context) ([]Message, error) {
+	wait := c.PollWait
+	if wait <= 0 {
+		wait = DefaultPollWait
+	}
+
+	// Polling with the last received sequence number acknowledges all messages
+	// up to it, which the server then drops.
+	u := c.messagesURL() + "/" + url.PathEscape(c.PartyID) +
+		"?wait=" + url.QueryEscape(wait.String()) + "&after=" + strconv.FormatUint(c.after, 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	var messages []Message
+	if err := json.NewDecoder(resp.Body).Decode(&messages); err != nil {
+		return nil, fmt.Errorf("relay: decode messages: %w", err)
+	}
+
+	// Skip messages which an earlier, interrupted poll already returned.
+	fresh := messages[:0]
+
+	for _, msg := range messages {
+		if msg.Seq > c.after {
+			fresh = append(fresh, msg)
+			c.after = msg.Seq
+		}
+	}
+
+	return fresh, nil
+}
+
+// Close drops all mailboxes of the session on the relay server.
+func (c *Client) Close(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.BaseURL+"/sessions/"+url.PathEscape(c.SessionID), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkStatus(resp, http.StatusNoContent)
+}
+
+func checkStatus(resp *http.Response, want int) error {
+	if resp.StatusCode == want {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	return fmt.Errorf("relay: %s: %s", resp.Status, bytes.TrimSpace(body))
+}
@@ -0,0 +1,236 @@
+package relay_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vultisig/go-wrappers/mpc"
+	"github.com/vultisig/go-wrappers/mpc/relay"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelayKeygen(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(relay.NewServer())
+	defer srv.Close()
+
+	setup, err := mpc.ECDSA.KeygenSetupMsgNew(2, nil, []string{"p1", "p2", "p3"})
+	assert.NoError(t, err)
+
+	ids, err := relay.Parties(setup)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"p1", "p2", "p3"}, ids)
+
+	sessionID := relay.SessionID(setup)
+	shares := make([]mpc.Keyshare, len(ids))
+	errs := make([]error, len(ids))
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+
+		go func(i int, id string) {
+			defer wg.Done()
+
+			session, err := mpc.ECDSA.KeygenSessionFromSetup(setup, id)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			client := relay.NewClient(srv.URL, sessionID, id)
+			client.PollWait = time.Second
+
+			d := &mpc.Driver{ID: id, Parties: ids, Transport: client, Timeout: time.Minute}
+			shares[i], errs[i] = mpc.Drive[mpc.Keyshare](context.Background(), d, session)
+		}(i, id)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+
+	publicKey, err := shares[0].PublicKey()
+	assert.NoError(t, err)
+
+	for _, share := range shares[1:] {
+		pk, err := share.PublicKey()
+		assert.NoError(t, err)
+		assert.Equal(t, publicKey, pk)
+	}
+
+	assert.NoError(t, relay.NewClient(srv.URL, sessionID, "p1").Close(context.Background()))
+
+	for _, share := range shares {
+		assert.NoError(t, share.Close())
+	}
+}
+
+func TestRelayPoll(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(relay.NewServer())
+	defer srv.Close()
+
+	alice := relay.NewClient(srv.URL, "s1", "alice")
+	bob := relay.NewClient(srv.URL, "s1", "bob")
+	bob.PollWait = 100 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	// No message arrives: the poll times out on the server and is retried
+	// until the context is done.
+	_, _, err := bob.Receive(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		assert.NoError(t, alice.Send(context.Background(), "bob", []byte("hello")))
+	}()
+
+	sender, msg, err := bob.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", sender)
+	assert.Equal(t, []byte("hello"), msg)
+
+	// Mailboxes of other sessions are separate.
+	other := relay.NewClient(srv.URL, "s2", "alice")
+	assert.NoError(t, other.Send(context.Background(), "bob", []byte("other")))
+	assert.NoError(t, alice.Send(context.Background(), "bob", []byte("again")))
+
+	_, msg, err = bob.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("again"), msg)
+}
+
+func getMessages(t *testing.T, url string) []relay.Message {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	assert.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var messages []relay.Message
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&messages))
+
+	return messages
+}
+
+func postMessage(t *testing.T, url string, msg relay.Message) int {
+	t.Helper()
+
+	body, err := json.Marshal(msg)
+	assert.NoError(t, err)
+
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	assert.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+func TestRelayAcknowledgedDelivery(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(relay.NewServer())
+	defer srv.Close()
+
+	post := srv.URL + "/sessions/s1/messages"
+	get := srv.URL + "/sessions/s1/messages/bob"
+
+	assert.Equal(t, http.StatusNoContent, postMessage(t, post, relay.Message{From: "alice", To: "bob", Body: []byte("m1")}))
+	assert.Equal(t, http.StatusNoContent, postMessage(t, post, relay.Message{From: "alice", To: "bob", Body: []byte("m2")}))
+
+	// A poll whose response never reaches the client does not lose the messages.
+	messages := getMessages(t, get)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, messages, getMessages(t, get))
+
+	// Acknowledging the first message drops it.
+	messages = getMessages(t, get+"?after="+strconv.FormatUint(messages[0].Seq, 10))
+	assert.Len(t, messages, 1)
+	assert.Equal(t, []byte("m2"), messages[0].Body)
+
+	messages = getMessages(t, get+"?after="+strconv.FormatUint(messages[0].Seq, 10))
+	assert.Empty(t, messages)
+
+	// The client acknowledges messages with its next poll.
+	assert.Equal(t, http.StatusNoContent, postMessage(t, post, relay.Message{From: "alice", To: "bob", Body: []byte("m3")}))
+
+	bob := relay.NewClient(srv.URL, "s1", "bob")
+
+	_, msg, err := bob.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("m3"), msg)
+
+	assert.Equal(t, http.StatusNoContent, postMessage(t, post, relay.Message{From: "alice", To: "bob", Body: []byte("m4")}))
+
+	_, msg, err = bob.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("m4"), msg)
+}
+
+func TestRelayLimits(t *testing.T) {
+	t.Parallel()
+
+	server := relay.NewServer()
+	server.MaxMessageSize = 256
+	server.MaxMailboxMessages = 2
+	server.MaxMailboxes = 2
+
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	post := srv.URL + "/sessions/s1/messages"
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge,
+		postMessage(t, post, relay.Message{From: "alice", To: "bob", Body: make([]byte, 256)}))
+
+	assert.Equal(t, http.StatusNoContent, postMessage(t, post, relay.Message{From: "alice", To: "bob"}))
+	assert.Equal(t, http.StatusNoContent, postMessage(t, post, relay.Message{From: "alice", To: "bob"}))
+	assert.Equal(t, http.StatusTooManyRequests, postMessage(t, post, relay.Message{From: "alice", To: "bob"}))
+
+	// Polls of empty mailboxes do not keep them.
+	for i := 0; i < 10; i++ {
+		assert.Empty(t, getMessages(t, srv.URL+"/sessions/s"+strconv.Itoa(i)+"/messages/carol"))
+	}
+
+	assert.Equal(t, http.StatusNoContent, postMessage(t, post, relay.Message{From: "alice", To: "carol"}))
+	assert.Equal(t, http.StatusServiceUnavailable, postMessage(t, post, relay.Message{From: "alice", To: "dave"}))
+}
+
+func TestRelayMailboxTTL(t *testing.T) {
+	t.Parallel()
+
+	server := relay.NewServer()
+	server.MailboxTTL = 50 * time.Millisecond
+
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	post := srv.URL + "/sessions/s1/messages"
+
+	assert.Equal(t, http.StatusNoContent, postMessage(t, post, relay.Message{From: "alice", To: "bob", Body: []byte("stale")}))
+
+	time.Sleep(100 * time.Millisecond)
+
+	// The next request sweeps the idle mailbox.
+	assert.Equal(t, http.StatusNoContent, postMessage(t, post, relay.Message{From: "alice", To: "carol"}))
+	assert.Empty(t, getMessages(t, srv.URL+"/sessions/s1/messages/bob"))
+}
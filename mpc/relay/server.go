@@ -0,0 +1,360 @@
+// Package relay moves protocol messages between devices over HTTP.
+//
+// The Server keeps a mailbox for every party of every session, keyed by the
+// session ID and the party ID. The Client posts outbound messages to the
+// mailboxes of their receivers and long-polls its own mailbox; it implements
+// mpc.Transport and plugs into mpc.Driver. The server holds nothing but opaque
+// messages and can run in-process, e.g. behind an httptest.Server.
+//
+// Key functionalities include:
+// - An HTTP relay server with per-session, per-party mailboxes
+// - Size limits, idle mailbox expiry and acknowledged delivery
+// - A long-polling client transport
+// - Deriving the session ID and the party IDs from a setup message
+package relay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	session "github.com/vultisig/go-wrappers/go-dkls/sessions"
+)
+
+// MaxWait bounds the long-poll wait a client can request.
+const MaxWait = 30 * time.Second
+
+// Default limits of a Server.
+const (
+	// DefaultMaxMessageSize is the default size limit of a posted message body.
+	DefaultMaxMessageSize = 1 << 20
+	// DefaultMaxMailboxMessages is the default number of undelivered messages a mailbox holds.
+	DefaultMaxMailboxMessages = 1024
+	// DefaultMaxMailboxes is the default number of mailboxes a server holds.
+	DefaultMaxMailboxes = 1 << 16
+	// DefaultMailboxTTL is the default time after which an idle mailbox is dropped.
+	DefaultMailboxTTL = 10 * time.Minute
+)
+
+// Message is a protocol message in transit.
+type Message struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Body []byte `json:"body"`
+	// Seq is the sequence number of the message in the mailbox of its receiver,
+	// assigned by the server.
+	Seq uint64 `json:"seq,omitempty"`
+}
+
+type mailboxKey struct {
+	session string
+	party   string
+}
+
+type mailbox struct {
+	// messages are the undelivered messages, in sequence order.
+	messages []Message
+	// seq is the sequence number of the last message posted to the mailbox.
+	seq uint64
+	// notify is closed and replaced whenever a message arrives.
+	notify chan struct{}
+	// waiters is the number of pending long-polls.
+	waiters int
+	// active is the time of the last request to the mailbox.
+	active time.Time
+}
+
+// Server is an HTTP relay for protocol messages.
+//
+// Routes:
+//   - POST /sessions/{session}/messages - enqueues a Message for its receiver.
+//   - GET /sessions/{session}/messages/{party}?after=3&wait=5s - returns the messages of
+//     the party with a sequence number above after, waiting up to wait for the first one.
+//     Messages up to after have been delivered and are dropped.
+//   - DELETE /sessions/{session} - drops all mailboxes of the session.
+//
+// Messages stay in their mailbox until the receiver acknowledges them with the
+// after parameter of its next poll, so a poll which fails mid-response loses
+// nothing. Mailboxes idle for longer than MailboxTTL are dropped.
+type Server struct {
+	// MaxMessageSize is the size limit of a posted message body.
+	MaxMessageSize int64
+	// MaxMailboxMessages is the number of undelivered messages a mailbox holds.
+	MaxMailboxMessages int
+	// MaxMailboxes is the number of mailboxes the server holds.
+	MaxMailboxes int
+	// MailboxTTL is the time after which an idle mailbox is dropped.
+	MailboxTTL time.Duration
+
+	mu        sync.Mutex
+	mailboxes map[mailboxKey]*mailbox
+	lastSweep time.Time
+	mux       *http.ServeMux
+}
+
+// NewServer creates an empty relay server with the default limits.
+//
+// Returns:
+//   - *Server: the server, ready to be used as an http.Handler.
+func NewServer() *Server {
+	s := &Server{
+		MaxMessageSize:     DefaultMaxMessageSize,
+		MaxMailboxMessages: DefaultMaxMailboxMessages,
+		MaxMailboxes:       DefaultMaxMailboxes,
+		MailboxTTL:         DefaultMailboxTTL,
+		mailboxes:          make(map[mailboxKey]*mailbox),
+		mux:                http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("POST /sessions/{session}/messages", s.post)
+	s.mux.HandleFunc("GET /sessions/{session}/messages/{party}", s.get)
+	s.mux.HandleFunc("DELETE /sessions/{session}", s.delete)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// errTooManyMailboxes is returned when the server holds MaxMailboxes mailboxes.
+var errTooManyMailboxes = errors.New("relay: too many mailboxes")
+
+// mailbox returns the mailbox of a party, creating it if needed. s.mu must be held.
+func (s *Server) mailbox(key mailboxKey, now time.Time) (*mailbox, error) {
+	s.sweep(now)
+
+	m, ok := s.mailboxes[key]
+	if !ok {
+		if len(s.mailboxes) >= s.MaxMailboxes {
+			return nil, errTooManyMailboxes
+		}
+
+		m = &mailbox{notify: make(chan struct{})}
+		s.mailboxes[key] = m
+	}
+
+	m.active = now
+
+	return m, nil
+}
+
+// sweep drops the mailboxes which have been idle for longer than MailboxTTL
+// and have no pending long-poll. s.mu must be held.
+func (s *Server) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.MailboxTTL/2 {
+		return
+	}
+
+	s.lastSweep = now
+
+	for key, m := range s.mailboxes {
+		if m.waiters == 0 && now.Sub(m.active) > s.MailboxTTL {
+			delete(s.mailboxes, key)
+		}
+	}
+}
+
+// release drops a mailbox which a long-poll created if it is still empty. s.mu must be held.
+func (s *Server) release(key mailboxKey, m *mailbox) {
+	m.waiters--
+
+	if m.waiters == 0 && len(m.messages) == 0 && m.seq == 0 && s.mailboxes[key] == m {
+		delete(s.mailboxes, key)
+	}
+}
+
+func (s *Server) post(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.MaxMessageSize)
+
+	var msg Message
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if msg.From == "" || msg.To == "" {
+		http.Error(w, "sender and receiver are required", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, err := s.mailbox(mailboxKey{session: r.PathValue("session"), party: msg.To}, time.Now().UTC())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	if len(m.messages) >= s.MaxMailboxMessages {
+		http.Error(w, "mailbox is full", http.StatusTooManyRequests)
+		return
+	}
+
+	m.seq++
+	msg.Seq = m.seq
+	m.messages = append(m.messages, msg)
+	close(m.notify)
+	m.notify = make(chan struct{})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	var (
+		wait  time.Duration
+		after uint64
+		err   error
+	)
+
+	query := r.URL.Query()
+
+	if v := query.Get("wait"); v != "" {
+		if wait, err = time.ParseDuration(v); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if v := query.Get("after"); v != "" {
+		if after, err = strconv.ParseUint(v, 10, 64); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	wait = min(wait, MaxWait)
+
+	key := mailboxKey{session: r.PathValue("session"), party: r.PathValue("party")}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	s.mu.Lock()
+	m, err := s.mailbox(key, time.Now().UTC())
+	if err != nil {
+		s.mu.Unlock()
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+
+		return
+	}
+
+	m.waiters++
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.release(key, m)
+		s.mu.Unlock()
+	}()
+
+	for {
+		s.mu.Lock()
+		if s.mailboxes[key] != m {
+			// The session has been deleted or the mailbox has expired.
+			s.mu.Unlock()
+			writeJSON(w, nil)
+
+			return
+		}
+
+		// Drop the messages the receiver acknowledged.
+		for len(m.messages) > 0 && m.messages[0].Seq <= after {
+			m.messages = m.messages[1:]
+		}
+
+		messages, notify := append([]Message(nil), m.messages...), m.notify
+		m.active = time.Now().UTC()
+		s.mu.Unlock()
+
+		if len(messages) > 0 || wait <= 0 {
+			writeJSON(w, messages)
+			return
+		}
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			wait = 0
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("session")
+
+	s.mu.Lock()
+	for key, m := range s.mailboxes {
+		if key.session == id {
+			// Wake up pending long-polls of the session.
+			close(m.notify)
+			m.notify = make(chan struct{})
+			delete(s.mailboxes, key)
+		}
+	}
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, messages []Message) {
+	if messages == nil {
+		messages = []Message{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(messages)
+}
+
+// SessionID derives a relay session ID from a setup message. All parties of
+// a session share the setup message and therefore the session ID.
+//
+// Parameters:
+//   - setup: []byte - the setup message of the session.
+//
+// Returns:
+//   - string: the hex encoded SHA-256 hash of the setup message.
+func SessionID(setup []byte) string {
+	sum := sha256.Sum256(setup)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Parties decodes the IDs of all parties from a DKLS setup message.
+//
+// Parameters:
+//   - setup: []byte - the setup message of the session.
+//
+// Returns:
+//   - []string: the party IDs in setup order.
+//   - error: an error if the setup message can not be decoded.
+func Parties(setup []byte) ([]string, error) {
+	var ids []string
+
+	for idx := 0; ; idx++ {
+		name, err := session.DklsDecodePartyName(setup, idx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(name) == 0 {
+			return ids, nil
+		}
+
+		ids = append(ids, string(name))
+	}
+}
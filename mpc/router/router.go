@@ -0,0 +1,209 @@
+// Package router runs all parties of an MPC session inside one process.
+//
+// Every party runs in its own goroutine with its own inbox, and messages are
+// routed to the receivers reported by the session, exactly as they would be
+// between separate devices. It is used by tests and by servers running all
+// co-signers of a vault in a single process.
+//
+// Key functionalities include:
+// - An in-memory Router implementing mpc.Transport for each party
+// - Running keygen, refresh, migration, QC, import and sign sessions of N parties concurrently
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vultisig/go-wrappers/mpc"
+)
+
+// ErrUnknownParty is returned when a message is addressed to a party the router does not know.
+var ErrUnknownParty = errors.New("router: unknown party")
+
+type envelope struct {
+	sender  string
+	message []byte
+}
+
+// inbox is an unbounded message queue of a single party. Sends never block,
+// so parties flushing their output to each other can not deadlock.
+type inbox struct {
+	mu     sync.Mutex
+	queue  []envelope
+	notify chan struct{}
+}
+
+func newInbox() *inbox {
+	return &inbox{notify: make(chan struct{}, 1)}
+}
+
+func (b *inbox) push(e envelope) {
+	b.mu.Lock()
+	b.queue = append(b.queue, e)
+	b.mu.Unlock()
+
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (b *inbox) pop(ctx context.Context) (envelope, error) {
+	for {
+		b.mu.Lock()
+		if len(b.queue) > 0 {
+			e := b.queue[0]
+			b.queue = b.queue[1:]
+			b.mu.Unlock()
+
+			return e, nil
+		}
+		b.mu.Unlock()
+
+		select {
+		case <-b.notify:
+		case <-ctx.Done():
+			return envelope{}, ctx.Err()
+		}
+	}
+}
+
+// Router delivers messages between the parties of one session.
+type Router struct {
+	inboxes map[string]*inbox
+}
+
+// New creates a router for the given parties.
+//
+// Parameters:
+//   - ids: []string - the IDs of all parties of the session.
+//
+// Returns:
+//   - *Router: the router.
+func New(ids []string) *Router {
+	inboxes := make(map[string]*inbox, len(ids))
+	for _, id := range ids {
+		inboxes[id] = newInbox()
+	}
+
+	return &Router{inboxes: inboxes}
+}
+
+// Transport returns the transport of the party with the given ID.
+//
+// Parameters:
+//   - id: string - the ID of the party.
+//
+// Returns:
+//   - mpc.Transport: the transport of the party.
+func (r *Router) Transport(id string) mpc.Transport {
+	return &transport{router: r, id: id}
+}
+
+type transport struct {
+	router *Router
+	id     string
+}
+
+func (t *transport) Send(_ context.Context, receiver string, message []byte) error {
+	b, ok := t.router.inboxes[receiver]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownParty, receiver)
+	}
+
+	b.push(envelope{sender: t.id, message: message})
+
+	return nil
+}
+
+func (t *transport) Receive(ctx context.Context) (string, []byte, error) {
+	b, ok := t.router.inboxes[t.id]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s", ErrUnknownParty, t.id)
+	}
+
+	e, err := b.pop(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return e.sender, e.message, nil
+}
+
+// Run runs the sessions of all parties concurrently, each in its own goroutine,
+// and returns their results. If any party fails, the others are cancelled and
+// the results of the parties which finished are closed if they implement
+// io.Closer, so no keyshare is leaked. All sessions are closed when Run returns.
+//
+// Parameters:
+//   - ctx: context.Context - cancels the run.
+//   - sessions: map[string]mpc.Finisher[T] - the session of every party, keyed by party ID.
+//   - timeout: time.Duration - bounds the run of every party. Zero means no timeout.
+//
+// Returns:
+//   - map[string]T: the result of every party, keyed by party ID.
+//   - error: the errors of the failed parties, joined.
+func Run[T any](ctx context.Context, sessions map[string]mpc.Finisher[T], timeout time.Duration) (map[string]T, error) {
+	ids := make([]string, 0, len(sessions))
+	for id := range sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	r := New(ids)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]T, len(sessions))
+		errs    []error
+	)
+
+	for id, session := range sessions {
+		wg.Add(1)
+
+		go func(id string, session mpc.Finisher[T]) {
+			defer wg.Done()
+
+			d := &mpc.Driver{ID: id, Parties: ids, Transport: r.Transport(id), Timeout: timeout}
+
+			result, err := mpc.Drive(ctx, d, session)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, fmt.Errorf("party %s: %w", id, err))
+				cancel()
+
+				return
+			}
+
+			results[id] = result
+		}(id, session)
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		for id, result := range results {
+			if closer, ok := any(result).(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					errs = append(errs, fmt.Errorf("party %s: close result: %w", id, err))
+				}
+			}
+		}
+
+		return nil, errors.Join(errs...)
+	}
+
+	return results, nil
+}
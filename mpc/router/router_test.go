@@ -0,0 +1,199 @@
+package router_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/vultisig/go-wrappers/mpc"
+	"github.com/vultisig/go-wrappers/mpc/router"
+
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	msg := make([]byte, 32)
+	for i := range msg {
+		msg[i] = 7
+	}
+
+	testCases := []struct {
+		name   string
+		scheme mpc.Scheme
+		verify func(publicKey []byte, signature []byte) bool
+	}{
+		{
+			name:   "ecdsa keygen 2x3 and sign",
+			scheme: mpc.ECDSA,
+			verify: func(publicKey []byte, signature []byte) bool {
+				return secp256k1.VerifySignature(publicKey, msg, signature[:64])
+			},
+		},
+		{
+			name:   "eddsa keygen 2x3 and sign",
+			scheme: mpc.EdDSA,
+			verify: func(publicKey []byte, signature []byte) bool {
+				return ed25519.Verify(publicKey, msg, signature)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ids := []string{"p1", "p2", "p3"}
+
+			setup, err := tc.scheme.KeygenSetupMsgNew(2, nil, ids)
+			assert.NoError(t, err)
+
+			keygen := make(map[string]mpc.Finisher[mpc.Keyshare], len(ids))
+			for _, id := range ids {
+				keygen[id], err = tc.scheme.KeygenSessionFromSetup(setup, id)
+				assert.NoError(t, err)
+			}
+
+			shares, err := router.Run(context.Background(), keygen, 0)
+			assert.NoError(t, err)
+			assert.Len(t, shares, len(ids))
+
+			publicKey, err := shares["p1"].PublicKey()
+			assert.NoError(t, err)
+
+			keyID, err := shares["p1"].KeyID()
+			assert.NoError(t, err)
+
+			signers := []string{"p2", "p3"}
+
+			setup, err = tc.scheme.SignSetupMsgNew(keyID, nil, msg, signers)
+			assert.NoError(t, err)
+
+			sign := make(map[string]mpc.Finisher[[]byte], len(signers))
+			for _, id := range signers {
+				sign[id], err = tc.scheme.SignSessionFromSetup(setup, id, shares[id])
+				assert.NoError(t, err)
+			}
+
+			signatures, err := router.Run(context.Background(), sign, 0)
+			assert.NoError(t, err)
+
+			for _, signature := range signatures {
+				assert.True(t, tc.verify(publicKey, signature))
+			}
+
+			for _, share := range shares {
+				assert.NoError(t, share.Close())
+			}
+		})
+	}
+}
+
+func TestUnknownParty(t *testing.T) {
+	t.Parallel()
+
+	r := router.New([]string{"p1", "p2"})
+
+	err := r.Transport("p1").Send(context.Background(), "p3", []byte{1})
+	assert.ErrorIs(t, err, router.ErrUnknownParty)
+}
+
+// result records whether it was closed.
+type result struct {
+	closed bool
+}
+
+func (r *result) Close() error {
+	r.closed = true
+
+	return nil
+}
+
+// finishingSession sends one message to its own party and finishes with res
+// once it receives it.
+type finishingSession struct {
+	id       string
+	res      *result
+	sent     bool
+	finished chan struct{}
+}
+
+func (s *finishingSession) OutputMessage() ([]byte, error) {
+	if s.sent {
+		return nil, nil
+	}
+
+	s.sent = true
+
+	return []byte{1}, nil
+}
+
+func (s *finishingSession) MessageReceiver(_ []byte, index int) (string, error) {
+	if index == 0 {
+		return s.id, nil
+	}
+
+	return "", nil
+}
+
+func (s *finishingSession) InputMessage([]byte) (bool, error) {
+	return true, nil
+}
+
+func (s *finishingSession) Finish() (*result, error) {
+	close(s.finished)
+
+	return s.res, nil
+}
+
+func (s *finishingSession) Close() error {
+	return nil
+}
+
+// failingSession fails once the other party has finished.
+type failingSession struct {
+	finished chan struct{}
+}
+
+var errFailed = errors.New("failed")
+
+func (s *failingSession) OutputMessage() ([]byte, error) {
+	<-s.finished
+
+	return nil, errFailed
+}
+
+func (s *failingSession) MessageReceiver([]byte, int) (string, error) {
+	return "", nil
+}
+
+func (s *failingSession) InputMessage([]byte) (bool, error) {
+	return false, nil
+}
+
+func (s *failingSession) Finish() (*result, error) {
+	return nil, errFailed
+}
+
+func (s *failingSession) Close() error {
+	return nil
+}
+
+func TestRunClosesFinishedResultsOnFailure(t *testing.T) {
+	t.Parallel()
+
+	finished := make(chan struct{})
+	res := &result{}
+
+	results, err := router.Run(context.Background(), map[string]mpc.Finisher[*result]{
+		"p1": &finishingSession{id: "p1", res: res, finished: finished},
+		"p2": &failingSession{finished: finished},
+	}, 0)
+	assert.ErrorIs(t, err, errFailed)
+	assert.Nil(t, results)
+	assert.True(t, res.closed)
+}
@@ -0,0 +1,193 @@
+// Package mpc provides a curve-agnostic interface over the go-dkls (ECDSA) and
+// go-schnorr (EdDSA) wrappers.
+//
+// The two wrapper packages expose parallel but slightly different APIs. Scheme
+// hides these differences so that vault code can run keygen, refresh, migration,
+// QC, signing, key export and key import for both key types through one code path.
+//
+// Key functionalities include:
+// - The Scheme interface and its ECDSA and EdDSA implementations
+// - Scheme-neutral Keyshare, Session, KeygenSession, SignSession and ExportReceiver types
+// - Keyshare serialization
+// - A Driver running any session to completion over a pluggable Transport
+// - Recovering a full keyshare from a compact refresh share backup
+//
+// Like go-dkls and go-schnorr, the package links libgodkls and libgoschnorr
+// from includes/. Binaries and tests outside the sessions directories find them
+// through LD_LIBRARY_PATH or an installed copy.
+package mpc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kind identifies a signature scheme.
+type Kind string
+
+const (
+	KindECDSA Kind = "ecdsa"
+	KindEdDSA Kind = "eddsa"
+)
+
+var (
+	// ErrSchemeMismatch is returned when a keyshare of one scheme is passed to the other.
+	ErrSchemeMismatch = errors.New("mpc: keyshare belongs to a different scheme")
+	// ErrUnsupported is returned for operations the backing library of a scheme does not provide.
+	ErrUnsupported = errors.New("mpc: operation not supported by the scheme")
+)
+
+// Keyshare is a keyshare of either scheme.
+type Keyshare interface {
+	// Scheme returns the kind of the scheme the keyshare belongs to.
+	Scheme() Kind
+	ToBytes() ([]byte, error)
+	PublicKey() ([]byte, error)
+	KeyID() ([]byte, error)
+	ChainCode() ([]byte, error)
+	// Info returns the quorum the keyshare belongs to.
+	Info() (KeyshareInfo, error)
+	// Close releases the keyshare. It is safe to call Close more than once.
+	// go-schnorr has no function releasing keyshares: closing an EdDSA keyshare
+	// makes it unusable, but the native object lives as long as the process.
+	Close() error
+}
+
+// KeyshareInfo describes the quorum a keyshare belongs to. PartyIndex is the
+// position of the owner in the ids list of the setup message which produced the
+// share, or for a QC its position among the new parties.
+type KeyshareInfo struct {
+	Threshold  int
+	Parties    int
+	PartyIndex int
+	// PartyNames are the names of the parties by party index. The native
+	// keyshares hold no party names, so they are taken from the setup message
+	// of the session which produced the keyshare; a keyshare restored with
+	// KeyshareFromBytes or wrapped with NewECDSAKeyshare or NewEdDSAKeyshare
+	// has none.
+	PartyNames []string
+}
+
+// setupPartyNames returns the names of the parties of the keyshares produced by
+// a setup message: its parties, or for a QC its new parties.
+func setupPartyNames(parties []string, newParties []int) []string {
+	if len(newParties) == 0 {
+		return parties
+	}
+
+	names := make([]string, len(newParties))
+	for i, idx := range newParties {
+		names[i] = parties[idx]
+	}
+
+	return names
+}
+
+// Session is a running protocol session driven by exchanging messages.
+type Session interface {
+	// OutputMessage returns the next output message, or an empty slice if there is none.
+	OutputMessage() ([]byte, error)
+	// MessageReceiver returns the receiver of a message with the given index,
+	// or an empty string if there are no more receivers.
+	MessageReceiver(message []byte, index int) (string, error)
+	// InputMessage processes an input message and reports whether the session has finished.
+	InputMessage(message []byte) (bool, error)
+	// Close releases the session. It is safe to call Close more than once.
+	Close() error
+}
+
+// KeygenSession is a session producing a keyshare: keygen, refresh, migration,
+// QC and key import.
+type KeygenSession interface {
+	Session
+	Finish() (Keyshare, error)
+}
+
+// SignSession is a session producing a signature. Finish verifies the signature
+// against the public key of the signing key, derived at the derivation path of
+// the setup message, and fails with ErrInvalidSignature of go-dkls or go-schnorr
+// if it does not match.
+type SignSession interface {
+	Session
+	Finish() ([]byte, error)
+}
+
+// ExportReceiver is the receiving side of a key export.
+type ExportReceiver interface {
+	// InputMessage processes a message from a key exporter and reports whether
+	// all expected messages have been received.
+	InputMessage(message []byte) (bool, error)
+	// Finish returns the exported private key.
+	Finish() ([]byte, error)
+	Close() error
+}
+
+// Scheme is a threshold signature scheme.
+type Scheme interface {
+	// Kind returns the kind of the scheme.
+	Kind() Kind
+
+	// KeygenSetupMsgNew creates a setup message for keygen, refresh and migration.
+	// keyID is nil for a fresh keygen and the key ID of the existing key otherwise.
+	KeygenSetupMsgNew(threshold int, keyID []byte, ids []string) ([]byte, error)
+	KeygenSessionFromSetup(setup []byte, id string) (KeygenSession, error)
+	// KeyRefreshSessionFromSetup creates a key refresh session from a keyshare.
+	// A refresh with fewer parties than the key has is limited by go-dkls to the
+	// parties of the lowest party indices, e.g. p1 and p2 of a 2-of-3 key in any
+	// order; a party outside of them gets ErrUnsupported before any round runs.
+	// EdDSA refreshes need every party.
+	KeyRefreshSessionFromSetup(setup []byte, id string, oldKeyshare Keyshare) (KeygenSession, error)
+	KeyMigrateSessionFromSetup(setup []byte, id string, publicKey []byte, rootChainCode []byte, secretCoefficient []byte) (KeygenSession, error)
+
+	// KeyshareToRefreshBytes serializes the compact refresh share of a keyshare,
+	// a backup which KeyRecoverySessionFromSetup turns back into a full keyshare.
+	KeyshareToRefreshBytes(keyshare Keyshare) ([]byte, error)
+	// KeyRecoverySessionFromSetup creates a key refresh session from a refresh share
+	// backup. The co-signers run KeyRefreshSessionFromSetup on the same setup, and
+	// the subset of parties is limited as for KeyRefreshSessionFromSetup.
+	KeyRecoverySessionFromSetup(setup []byte, id string, refreshShare []byte) (KeygenSession, error)
+
+	// QcSetupMsgNew creates a quorum change setup message.
+	QcSetupMsgNew(keyshare Keyshare, threshold int, ids []string, oldParties []int, newParties []int) ([]byte, error)
+	// QcSessionFromSetup creates a QC session. keyshare is nil for a party joining the quorum.
+	QcSessionFromSetup(setup []byte, id string, keyshare Keyshare) (KeygenSession, error)
+
+	SignSetupMsgNew(keyID []byte, chainPath []byte, message []byte, ids []string) ([]byte, error)
+	// SignSessionFromSetup creates a sign session for the child key of the chain
+	// path of setup. keyshare is the root keyshare; it is not modified and stays
+	// owned by the caller.
+	SignSessionFromSetup(setup []byte, id string, keyshare Keyshare) (SignSession, error)
+
+	KeyExportReceiverNew(keyshare Keyshare, ids []string) (ExportReceiver, []byte, error)
+	// KeyExporter returns the encrypted share of the private key and the ID of its receiver.
+	KeyExporter(keyshare Keyshare, id string, setup []byte) ([]byte, string, error)
+
+	KeyImportInitiatorNew(privateKey []byte, rootChain []byte, threshold int, ids []string) (KeygenSession, []byte, error)
+	KeyImporterNew(setup []byte, id string) (KeygenSession, error)
+
+	KeyshareFromBytes(buf []byte) (Keyshare, error)
+}
+
+// ByKind returns the scheme of the given kind.
+//
+// Parameters:
+//   - kind: Kind - the kind of the scheme.
+//
+// Returns:
+//   - Scheme: the scheme.
+//   - error: an error if the kind is unknown.
+func ByKind(kind Kind) (Scheme, error) {
+	switch kind {
+	case KindECDSA:
+		return ECDSA, nil
+	case KindEdDSA:
+		return EdDSA, nil
+	default:
+		return nil, fmt.Errorf("mpc: unknown scheme %q", kind)
+	}
+}
+
+func joinIDs(ids []string) []byte {
+	return []byte(strings.Join(ids, "\x00"))
+}
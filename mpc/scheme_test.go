@@ -0,0 +1,361 @@
+package mpc_test
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"testing"
+
+	dklsErrors "github.com/vultisig/go-wrappers/go-dkls/errors"
+	schnorrErrors "github.com/vultisig/go-wrappers/go-schnorr/errors"
+	schnorr "github.com/vultisig/go-wrappers/go-schnorr/sessions"
+	"github.com/vultisig/go-wrappers/mpc"
+
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+	"github.com/stretchr/testify/assert"
+)
+
+func partyIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("p%d", i+1)
+	}
+
+	return ids
+}
+
+// runLoop routes messages between the sessions until all of them finish.
+func runLoop(t *testing.T, ids []string, sessions []mpc.Session) {
+	finished := make([]bool, len(sessions))
+	done := 0
+
+	for done != len(sessions) {
+		msgq := make(map[string][][]byte)
+
+		for _, s := range sessions {
+			for {
+				msg, err := s.OutputMessage()
+				assert.NoError(t, err)
+
+				if len(msg) == 0 {
+					break
+				}
+
+				for idx := 0; idx < len(ids); idx++ {
+					receiver, err := s.MessageReceiver(msg, idx)
+					assert.NoError(t, err)
+
+					if receiver == "" {
+						break
+					}
+
+					msgq[receiver] = append(msgq[receiver], msg)
+				}
+			}
+		}
+
+		for i, s := range sessions {
+			for _, msg := range msgq[ids[i]] {
+				f, err := s.InputMessage(msg)
+				assert.NoError(t, err)
+
+				if f && !finished[i] {
+					finished[i] = true
+					done++
+				}
+			}
+		}
+	}
+}
+
+func runKeygen(t *testing.T, scheme mpc.Scheme, threshold int, n int) []mpc.Keyshare {
+	ids := partyIDs(n)
+
+	setup, err := scheme.KeygenSetupMsgNew(threshold, nil, ids)
+	assert.NoError(t, err)
+
+	keygen := make([]mpc.KeygenSession, n)
+	sessions := make([]mpc.Session, n)
+
+	for i, id := range ids {
+		keygen[i], err = scheme.KeygenSessionFromSetup(setup, id)
+		assert.NoError(t, err)
+
+		sessions[i] = keygen[i]
+	}
+
+	runLoop(t, ids, sessions)
+
+	shares := make([]mpc.Keyshare, n)
+	for i, s := range keygen {
+		shares[i], err = s.Finish()
+		assert.NoError(t, err)
+		assert.Equal(t, scheme.Kind(), shares[i].Scheme())
+		assert.NoError(t, s.Close())
+	}
+
+	return shares
+}
+
+func runSign(t *testing.T, scheme mpc.Scheme, shares []mpc.Keyshare, msg []byte) [][]byte {
+	return runSignWithChainPath(t, scheme, shares, nil, msg)
+}
+
+func runSignWithChainPath(t *testing.T, scheme mpc.Scheme, shares []mpc.Keyshare, chainPath []byte, msg []byte) [][]byte {
+	ids := partyIDs(len(shares))
+
+	keyID, err := shares[0].KeyID()
+	assert.NoError(t, err)
+
+	setup, err := scheme.SignSetupMsgNew(keyID, chainPath, msg, ids)
+	assert.NoError(t, err)
+
+	signers := make([]mpc.SignSession, len(shares))
+	sessions := make([]mpc.Session, len(shares))
+
+	for i, id := range ids {
+		signers[i], err = scheme.SignSessionFromSetup(setup, id, shares[i])
+		assert.NoError(t, err)
+
+		sessions[i] = signers[i]
+	}
+
+	runLoop(t, ids, sessions)
+
+	signatures := make([][]byte, len(shares))
+	for i, s := range signers {
+		signatures[i], err = s.Finish()
+		assert.NoError(t, err)
+		assert.NoError(t, s.Close())
+	}
+
+	return signatures
+}
+
+func TestSchemes(t *testing.T) {
+	t.Parallel()
+
+	msg := make([]byte, 32)
+	for i := range msg {
+		msg[i] = 5
+	}
+
+	testCases := []struct {
+		name   string
+		scheme mpc.Scheme
+		verify func(t *testing.T, publicKey []byte, signature []byte)
+	}{
+		{
+			name:   "ecdsa keygen and sign 2x3",
+			scheme: mpc.ECDSA,
+			verify: func(t *testing.T, publicKey []byte, signature []byte) {
+				assert.True(t, secp256k1.VerifySignature(publicKey, msg, signature[:64]))
+			},
+		},
+		{
+			name:   "eddsa keygen and sign 2x3",
+			scheme: mpc.EdDSA,
+			verify: func(t *testing.T, publicKey []byte, signature []byte) {
+				assert.True(t, ed25519.Verify(publicKey, msg, signature))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			shares := runKeygen(t, tc.scheme, 2, 3)
+
+			buf, err := shares[1].ToBytes()
+			assert.NoError(t, err)
+
+			restored, err := tc.scheme.KeyshareFromBytes(buf)
+			assert.NoError(t, err)
+
+			publicKey, err := restored.PublicKey()
+			assert.NoError(t, err)
+
+			info, err := restored.Info()
+			assert.NoError(t, err)
+			assert.Equal(t, mpc.KeyshareInfo{Threshold: 2, Parties: 3, PartyIndex: 1}, info)
+
+			signatures := runSign(t, tc.scheme, []mpc.Keyshare{shares[0], restored}, msg)
+			for _, signature := range signatures {
+				tc.verify(t, publicKey, signature)
+			}
+
+			for _, share := range append(shares, restored) {
+				assert.NoError(t, share.Close())
+			}
+		})
+	}
+}
+
+func TestSignDerivedKey(t *testing.T) {
+	t.Parallel()
+
+	msg := make([]byte, 32)
+	for i := range msg {
+		msg[i] = 7
+	}
+
+	chainPath := []byte("m/0/1")
+
+	testCases := []struct {
+		name   string
+		scheme mpc.Scheme
+		verify func(t *testing.T, share mpc.Keyshare, signature []byte)
+	}{
+		{
+			name:   "ecdsa sign with derived key",
+			scheme: mpc.ECDSA,
+			verify: func(t *testing.T, share mpc.Keyshare, signature []byte) {
+				s, err := mpc.ECDSAKeyshare(share)
+				assert.NoError(t, err)
+				assert.NoError(t, s.VerifySignature(chainPath, msg, signature))
+				assert.ErrorIs(t, s.VerifySignature(nil, msg, signature), dklsErrors.ErrInvalidSignature)
+			},
+		},
+		{
+			name:   "eddsa sign with derived key",
+			scheme: mpc.EdDSA,
+			verify: func(t *testing.T, share mpc.Keyshare, signature []byte) {
+				s, err := mpc.EdDSAKeyshare(share)
+				assert.NoError(t, err)
+				assert.NoError(t, schnorr.SchnorrVerifySignature(s, chainPath, msg, signature))
+				assert.ErrorIs(t, schnorr.SchnorrVerifySignature(s, nil, msg, signature), schnorrErrors.ErrInvalidSignature)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			shares := runKeygen(t, tc.scheme, 2, 2)
+
+			publicKey, err := shares[0].PublicKey()
+			assert.NoError(t, err)
+
+			// Finish verifies the signatures before returning them.
+			signatures := runSignWithChainPath(t, tc.scheme, shares, chainPath, msg)
+			for _, signature := range signatures {
+				tc.verify(t, shares[0], signature)
+			}
+
+			// The root keyshares are left unchanged and still sign for the root key.
+			pk, err := shares[0].PublicKey()
+			assert.NoError(t, err)
+			assert.Equal(t, publicKey, pk)
+
+			runSign(t, tc.scheme, shares, msg)
+
+			for _, share := range shares {
+				assert.NoError(t, share.Close())
+			}
+		})
+	}
+}
+
+func TestSchemeMismatch(t *testing.T) {
+	t.Parallel()
+
+	shares := runKeygen(t, mpc.EdDSA, 2, 2)
+
+	_, err := mpc.ECDSA.SignSessionFromSetup(nil, "p1", shares[0])
+	assert.ErrorIs(t, err, mpc.ErrSchemeMismatch)
+
+	_, err = mpc.ECDSAKeyshare(shares[0])
+	assert.ErrorIs(t, err, mpc.ErrSchemeMismatch)
+
+	scheme, err := mpc.ByKind(shares[0].Scheme())
+	assert.NoError(t, err)
+	assert.Equal(t, mpc.EdDSA, scheme)
+
+	_, err = mpc.ByKind("rsa")
+	assert.Error(t, err)
+}
+
+func TestKeyshareClose(t *testing.T) {
+	t.Parallel()
+
+	for _, scheme := range []mpc.Scheme{mpc.ECDSA, mpc.EdDSA} {
+		scheme := scheme
+		t.Run(string(scheme.Kind()), func(t *testing.T) {
+			t.Parallel()
+
+			shares := runKeygen(t, scheme, 2, 2)
+
+			assert.NoError(t, shares[0].Close())
+			assert.NoError(t, shares[0].Close())
+
+			_, err := shares[0].PublicKey()
+			assert.Error(t, err)
+
+			_, err = shares[1].PublicKey()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestKeyshareInfo(t *testing.T) {
+	t.Parallel()
+
+	for _, scheme := range []mpc.Scheme{mpc.ECDSA, mpc.EdDSA} {
+		scheme := scheme
+		t.Run(string(scheme.Kind()), func(t *testing.T) {
+			t.Parallel()
+
+			shares := runKeygen(t, scheme, 2, 3)
+
+			for i, share := range shares {
+				info, err := share.Info()
+				assert.NoError(t, err)
+				assert.Equal(t, mpc.KeyshareInfo{Threshold: 2, Parties: 3, PartyIndex: i, PartyNames: []string{"p1", "p2", "p3"}}, info)
+			}
+
+			// p1 leaves and p4 joins; the new parties are indexed in setup order.
+			ids := []string{"p1", "p2", "p3", "p4"}
+
+			setup, err := scheme.QcSetupMsgNew(shares[0], 2, ids, []int{0, 1, 2}, []int{1, 2, 3})
+			assert.NoError(t, err)
+
+			qc := make([]mpc.KeygenSession, len(ids))
+			sessions := make([]mpc.Session, len(ids))
+
+			for i, id := range ids {
+				var share mpc.Keyshare
+				if i < len(shares) {
+					share = shares[i]
+				}
+
+				qc[i], err = scheme.QcSessionFromSetup(setup, id, share)
+				assert.NoError(t, err)
+
+				sessions[i] = qc[i]
+			}
+
+			runLoop(t, ids, sessions)
+
+			for i, s := range qc[1:] {
+				share, err := s.Finish()
+				assert.NoError(t, err)
+
+				info, err := share.Info()
+				assert.NoError(t, err)
+				assert.Equal(t, mpc.KeyshareInfo{Threshold: 2, Parties: 3, PartyIndex: i, PartyNames: []string{"p2", "p3", "p4"}}, info)
+				assert.NoError(t, share.Close())
+			}
+
+			for _, s := range qc {
+				assert.NoError(t, s.Close())
+			}
+
+			for _, share := range shares {
+				assert.NoError(t, share.Close())
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+// Package signature converts DKLS ECDSA signatures into the encodings of
+// blockchains.
+//
+// DklsSignSessionFinish returns a signature as [ R || S || rec-id ]. Bitcoin and
+// Cosmos only accept signatures with an S value in the lower half of the curve
+// order, and Ethereum transactions carry the recovery ID in a chain-specific V
+// value. Negating S to normalize a signature negates the nonce point as well,
+// which flips the parity bit of the recovery ID. Signature keeps the two in sync.
+//
+// Key functionalities include:
+// - Parsing the DKLS [ R || S || rec-id ] output
+// - Enforcing low S and fixing the recovery ID
+// - Ethereum [ R || S || V ] with legacy, EIP-155 and typed transaction V values
+// - Bitcoin DER, compact 64-byte and Cosmos encodings
+// - Recomputing the recovery ID from the message hash and the public key
+package signature
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+)
+
+// ErrSignature is returned when a signature can not be parsed or does not match a public key.
+var ErrSignature = errors.New("signature: invalid signature")
+
+const (
+	scalarSize = 32
+	// Size is the size of a DKLS signature, [ R || S || rec-id ].
+	Size = 2*scalarSize + 1
+
+	// ethereumLegacyV is the V value of a legacy Ethereum signature with recovery ID 0.
+	ethereumLegacyV = 27
+	// eip155Offset is added to twice the chain ID in an EIP-155 V value.
+	eip155Offset = 35
+)
+
+var (
+	curveOrder     = secp256k1.S256().N
+	halfCurveOrder = new(big.Int).Rsh(curveOrder, 1)
+)
+
+// Signature is a secp256k1 ECDSA signature with its recovery ID.
+type Signature struct {
+	R *big.Int
+	S *big.Int
+	// RecoveryID is the parity of the y coordinate of the nonce point, plus 2
+	// if its x coordinate overflowed the curve order.
+	RecoveryID byte
+}
+
+// Parse parses a signature returned by DklsSignSessionFinish.
+//
+// Parameters:
+//   - sig: []byte - the signature, [ R || S || rec-id ].
+//
+// Returns:
+//   - Signature: the parsed signature.
+//   - error: ErrSignature if the signature is malformed.
+func Parse(sig []byte) (Signature, error) {
+	if len(sig) != Size {
+		return Signature{}, fmt.Errorf("%w: got %d bytes, want %d", ErrSignature, len(sig), Size)
+	}
+
+	r := new(big.Int).SetBytes(sig[:scalarSize])
+	s := new(big.Int).SetBytes(sig[scalarSize : 2*scalarSize])
+
+	if !validScalar(r) || !validScalar(s) {
+		return Signature{}, fmt.Errorf("%w: R or S out of range", ErrSignature)
+	}
+
+	if sig[2*scalarSize] > 3 {
+		return Signature{}, fmt.Errorf("%w: invalid recovery ID %d", ErrSignature, sig[2*scalarSize])
+	}
+
+	return Signature{R: r, S: s, RecoveryID: sig[2*scalarSize]}, nil
+}
+
+// IsLowS reports whether S is in the lower half of the curve order.
+func (s Signature) IsLowS() bool {
+	return s.S.Cmp(halfCurveOrder) <= 0
+}
+
+// Normalize returns the signature with a low S. If S is negated, the parity
+// bit of the recovery ID is flipped so that the signature recovers the same key.
+func (s Signature) Normalize() Signature {
+	if s.IsLowS() {
+		return s
+	}
+
+	return Signature{
+		R:          s.R,
+		S:          new(big.Int).Sub(curveOrder, s.S),
+		RecoveryID: s.RecoveryID ^ 1,
+	}
+}
+
+// Bytes returns the signature as [ R || S || rec-id ], as returned by DklsSignSessionFinish.
+func (s Signature) Bytes() []byte {
+	return append(s.rs(), s.RecoveryID)
+}
+
+func (s Signature) rs() []byte {
+	buf := make([]byte, 2*scalarSize, Size)
+	s.R.FillBytes(buf[:scalarSize])
+	s.S.FillBytes(buf[scalarSize:])
+
+	return buf
+}
+
+// Compact returns the normalized signature as the 64-byte [ R || S ].
+func (s Signature) Compact() []byte {
+	return s.Normalize().rs()
+}
+
+// Cosmos returns the signature of a Cosmos SDK transaction, the 64-byte
+// [ R || S ] with a low S.
+func (s Signature) Cosmos() []byte {
+	return s.Compact()
+}
+
+// DER returns the normalized signature in the ASN.1 DER encoding of Bitcoin.
+// The sighash type byte is not included.
+//
+// Returns:
+//   - []byte: the DER encoded signature.
+//   - error: ErrSignature if R or S is not in the range [1, n-1] of the curve order n.
+func (s Signature) DER() ([]byte, error) {
+	if !validScalar(s.R) || !validScalar(s.S) {
+		return nil, fmt.Errorf("%w: R or S out of range", ErrSignature)
+	}
+
+	n := s.Normalize()
+
+	der, err := asn1.Marshal(struct{ R, S *big.Int }{n.R, n.S})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSignature, err)
+	}
+
+	return der, nil
+}
+
+// validScalar reports whether v is a valid R or S value, in the range [1, n-1].
+func validScalar(v *big.Int) bool {
+	return v != nil && v.Sign() > 0 && v.Cmp(curveOrder) < 0
+}
+
+// YParity returns the parity of the y coordinate of the nonce point of the
+// normalized signature, the V value of typed Ethereum transactions (EIP-2718).
+func (s Signature) YParity() byte {
+	return s.Normalize().RecoveryID & 1
+}
+
+// Ethereum returns the normalized signature as the 65-byte [ R || S || V ] with
+// V = 27 + y parity, as used by legacy transactions and personal_sign.
+func (s Signature) Ethereum() []byte {
+	return append(s.Compact(), ethereumLegacyV+s.YParity())
+}
+
+// EthereumTyped returns the normalized signature as the 65-byte [ R || S || V ]
+// with V = y parity, as used by typed transactions and ecrecover in go-ethereum.
+func (s Signature) EthereumTyped() []byte {
+	return append(s.Compact(), s.YParity())
+}
+
+// EIP155V returns the V value of a legacy transaction replay-protected by
+// EIP-155: chainID * 2 + 35 + y parity. It may not fit in a byte.
+//
+// Parameters:
+//   - chainID: *big.Int - the chain ID, e.g. 1 for Ethereum mainnet.
+//
+// Returns:
+//   - *big.Int: the V value.
+func (s Signature) EIP155V(chainID *big.Int) *big.Int {
+	v := new(big.Int).Lsh(chainID, 1)
+	v.Add(v, big.NewInt(eip155Offset))
+
+	return v.Add(v, big.NewInt(int64(s.YParity())))
+}
+
+// RecoverID recomputes the recovery ID of a signature by recovering the public
+// key of each candidate ID, and returns the signature with the matching ID.
+//
+// Parameters:
+//   - hash: []byte - the 32-byte signed message hash.
+//   - publicKey: []byte - the compressed or uncompressed public key of the signer.
+//
+// Returns:
+//   - Signature: the signature with the recovery ID of the public key.
+//   - error: ErrSignature if no recovery ID recovers the public key.
+func (s Signature) RecoverID(hash []byte, publicKey []byte) (Signature, error) {
+	var x, y *big.Int
+
+	switch {
+	case len(publicKey) == scalarSize+1:
+		x, y = secp256k1.DecompressPubkey(publicKey)
+	case len(publicKey) == 2*scalarSize+1:
+		x, y = secp256k1.S256().Unmarshal(publicKey)
+	}
+
+	if x == nil || !secp256k1.S256().IsOnCurve(x, y) {
+		return Signature{}, fmt.Errorf("%w: invalid public key", ErrSignature)
+	}
+
+	want := secp256k1.S256().Marshal(x, y)
+
+	for id := byte(0); id < 4; id++ {
+		candidate := Signature{R: s.R, S: s.S, RecoveryID: id}
+
+		recovered, err := secp256k1.RecoverPubkey(hash, candidate.Bytes())
+		if err == nil && bytes.Equal(recovered, want) {
+			return candidate, nil
+		}
+	}
+
+	return Signature{}, fmt.Errorf("%w: no recovery ID matches the public key", ErrSignature)
+}
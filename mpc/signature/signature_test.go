@@ -0,0 +1,214 @@
+package signature_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+
+	dkls "github.com/vultisig/go-wrappers/go-dkls/sessions"
+	testHelper "github.com/vultisig/go-wrappers/go-dkls/test"
+	"github.com/vultisig/go-wrappers/mpc/signature"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var curveOrder = secp256k1.S256().N
+
+// highS returns the equivalent high-S form of a signature, with S negated and
+// the parity of the recovery ID flipped, as a signer that does not normalize S
+// would return it.
+func highS(t *testing.T, sig []byte) []byte {
+	t.Helper()
+
+	parsed, err := signature.Parse(sig)
+	assert.NoError(t, err)
+
+	negated := signature.Signature{
+		R:          parsed.R,
+		S:          new(big.Int).Sub(curveOrder, parsed.S),
+		RecoveryID: parsed.RecoveryID ^ 1,
+	}
+	if negated.IsLowS() {
+		negated = parsed
+	}
+
+	return negated.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	sig := make([]byte, signature.Size)
+	sig[31], sig[63], sig[64] = 1, 2, 1
+
+	parsed, err := signature.Parse(sig)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), parsed.R.Int64())
+	assert.Equal(t, int64(2), parsed.S.Int64())
+	assert.Equal(t, byte(1), parsed.RecoveryID)
+	assert.Equal(t, sig, parsed.Bytes())
+
+	_, err = signature.Parse(sig[:64])
+	assert.ErrorIs(t, err, signature.ErrSignature)
+
+	invalid := append([]byte{}, sig...)
+	invalid[64] = 4
+	_, err = signature.Parse(invalid)
+	assert.ErrorIs(t, err, signature.ErrSignature)
+
+	invalid = append([]byte{}, sig...)
+	curveOrder.FillBytes(invalid[32:64])
+	_, err = signature.Parse(invalid)
+	assert.ErrorIs(t, err, signature.ErrSignature)
+
+	_, err = signature.Parse(make([]byte, signature.Size))
+	assert.ErrorIs(t, err, signature.ErrSignature)
+}
+
+func TestEncodings(t *testing.T) {
+	t.Parallel()
+
+	sig, err := hex.DecodeString(
+		"80000000000000000000000000000000000000000000000000000000000000ff" +
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+			"01")
+	assert.NoError(t, err)
+
+	parsed, err := signature.Parse(sig)
+	assert.NoError(t, err)
+
+	assert.Equal(t, sig[:64], parsed.Compact())
+	assert.Equal(t, sig[:64], parsed.Cosmos())
+	assert.Equal(t, append(sig[:64:64], 28), parsed.Ethereum())
+	assert.Equal(t, sig, parsed.EthereumTyped())
+	assert.Equal(t, byte(1), parsed.YParity())
+	assert.Equal(t, big.NewInt(38), parsed.EIP155V(big.NewInt(1)))
+	assert.Equal(t, big.NewInt(2*137+36), parsed.EIP155V(big.NewInt(137)))
+
+	// R has its high bit set and gets a leading zero byte.
+	der, err := parsed.DER()
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"3026"+"022100"+"80000000000000000000000000000000000000000000000000000000000000ff"+"020101",
+		hex.EncodeToString(der))
+}
+
+func TestDER(t *testing.T) {
+	t.Parallel()
+
+	// The signature of the input of Bitcoin transaction
+	// f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16 in block
+	// 170, without its sighash type byte, over its sighash by the key of the spent output.
+	want, _ := hex.DecodeString("304402204e45e16932b8af514961a1d3a1a25fdf3f4f7732e9d624c6c61548ab5fb8cd41" +
+		"0220181522ec8eca07de4860a4acdd12909d831cc56cbbac4622082221a8768d1d09")
+	sighash, _ := hex.DecodeString("7a05c6145f10101e9d6325494245adf1297d80f8f38d4d576d57cdba220bcb19")
+	publicKey, _ := hex.DecodeString("0411db93e1dcdb8a016b49840f8c53bc1eb68a382e97b1482ecad7b148a6909a5c" +
+		"b2e0eaddfb84ccf9744464f82e160bfa9b8b64f9d4c03f999b8643f656b412a3")
+
+	r, _ := new(big.Int).SetString("4e45e16932b8af514961a1d3a1a25fdf3f4f7732e9d624c6c61548ab5fb8cd41", 16)
+	s, _ := new(big.Int).SetString("181522ec8eca07de4860a4acdd12909d831cc56cbbac4622082221a8768d1d09", 16)
+
+	der, err := signature.Signature{R: r, S: s}.DER()
+	assert.NoError(t, err)
+	assert.Equal(t, want, der)
+
+	x, y := secp256k1.S256().Unmarshal(publicKey)
+	assert.True(t, ecdsa.VerifyASN1(&ecdsa.PublicKey{Curve: secp256k1.S256(), X: x, Y: y}, sighash, der))
+
+	// The high-S form of the signature is normalized to the same encoding.
+	der, err = signature.Signature{R: r, S: new(big.Int).Sub(curveOrder, s), RecoveryID: 1}.DER()
+	assert.NoError(t, err)
+	assert.Equal(t, want, der)
+
+	invalid := []signature.Signature{
+		{R: big.NewInt(0), S: s},
+		{R: r, S: big.NewInt(0)},
+		{R: r, S: new(big.Int).Neg(s)},
+		{R: curveOrder, S: s},
+		{S: s},
+		{R: r},
+	}
+
+	for _, sig := range invalid {
+		_, err := sig.DER()
+		assert.ErrorIs(t, err, signature.ErrSignature)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	low := signature.Signature{R: big.NewInt(5), S: big.NewInt(7), RecoveryID: 0}
+	assert.True(t, low.IsLowS())
+	assert.Equal(t, low, low.Normalize())
+
+	high := signature.Signature{R: big.NewInt(5), S: new(big.Int).Sub(curveOrder, big.NewInt(7)), RecoveryID: 2}
+	assert.False(t, high.IsLowS())
+
+	normalized := high.Normalize()
+	assert.Equal(t, big.NewInt(7), normalized.S)
+	assert.Equal(t, byte(3), normalized.RecoveryID)
+	assert.Equal(t, low.Compact(), high.Compact())
+	assert.Equal(t, byte(1), high.YParity())
+}
+
+func TestDklsSignature(t *testing.T) {
+	t.Parallel()
+
+	shares, err := testHelper.RunKeygen(2, 2)
+	assert.NoError(t, err)
+
+	publicKey, err := dkls.DklsKeysharePublicKey(shares[0])
+	assert.NoError(t, err)
+
+	x, y := secp256k1.DecompressPubkey(publicKey)
+	uncompressed := secp256k1.S256().Marshal(x, y)
+
+	hash := sha256.Sum256([]byte("signature encodings"))
+
+	sigs, err := testHelper.RunSign(shares, hash[:])
+	assert.NoError(t, err)
+
+	for _, sig := range [][]byte{sigs[0], highS(t, sigs[0])} {
+		parsed, err := signature.Parse(sig)
+		assert.NoError(t, err)
+
+		recovered, err := secp256k1.RecoverPubkey(hash[:], parsed.EthereumTyped())
+		assert.NoError(t, err)
+		assert.Equal(t, uncompressed, recovered)
+
+		ethereum := parsed.Ethereum()
+		assert.Equal(t, parsed.EthereumTyped()[64]+27, ethereum[64])
+
+		compact := parsed.Compact()
+		assert.True(t, secp256k1.VerifySignature(publicKey, hash[:], compact))
+
+		der, err := parsed.DER()
+		assert.NoError(t, err)
+		assert.True(t, ecdsa.VerifyASN1(&ecdsa.PublicKey{Curve: secp256k1.S256(), X: x, Y: y}, hash[:], der))
+
+		// A wrong recovery ID is recomputed from the public key.
+		wrong := signature.Signature{R: parsed.R, S: parsed.S, RecoveryID: parsed.RecoveryID ^ 1}
+		fixed, err := wrong.RecoverID(hash[:], publicKey)
+		assert.NoError(t, err)
+		assert.Equal(t, parsed.RecoveryID, fixed.RecoveryID)
+
+		fixed, err = wrong.RecoverID(hash[:], uncompressed)
+		assert.NoError(t, err)
+		assert.Equal(t, parsed.RecoveryID, fixed.RecoveryID)
+	}
+
+	parsed, err := signature.Parse(sigs[0])
+	assert.NoError(t, err)
+
+	otherHash := sha256.Sum256([]byte("another message"))
+	_, err = parsed.RecoverID(otherHash[:], publicKey)
+	assert.ErrorIs(t, err, signature.ErrSignature)
+
+	_, err = parsed.RecoverID(hash[:], publicKey[1:])
+	assert.ErrorIs(t, err, signature.ErrSignature)
+}
@@ -0,0 +1,343 @@
+package store
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	shareExt = ".share"
+	lockFile = "lock"
+	// highWaterFile holds the highest version ever assigned, so that versions
+	// removed by Rollback or Delete are not reused.
+	highWaterFile = "version"
+
+	lockInterval = 10 * time.Millisecond
+)
+
+// FS stores keyshares as files below a directory:
+//
+//	<dir>/<hex key ID>/<hex party ID>/<version>.share
+//	<dir>/<hex key ID>/<hex party ID>/version
+//
+// Files are written atomically with 0600 permissions. Locks are flock(2) locks
+// on a lock file next to the versions, so they exclude other processes as well.
+type FS struct {
+	dir string
+}
+
+// NewFS creates a filesystem store, creating dir if needed.
+//
+// Parameters:
+//   - dir: string - the root directory of the store.
+//
+// Returns:
+//   - *FS: the store.
+//   - error: an error if the directory can not be created.
+func NewFS(dir string) (*FS, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &FS{dir: dir}, nil
+}
+
+func (s *FS) path(key Key) string {
+	return filepath.Join(s.dir, hex.EncodeToString(key.KeyID), hex.EncodeToString([]byte(key.PartyID)))
+}
+
+func (s *FS) Put(_ context.Context, key Key, data []byte) (uint64, error) {
+	dir := s.path(key)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return 0, err
+	}
+
+	versions, err := s.versions(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	version, err := s.highWater(dir, versions)
+	if err != nil {
+		return 0, err
+	}
+
+	version++
+
+	tmp, err := os.CreateTemp(dir, "*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+
+	// Link rather than rename, so that a concurrent writer of the same version fails.
+	if err := os.Link(tmp.Name(), filepath.Join(dir, versionName(version))); err != nil {
+		return 0, err
+	}
+
+	if err := s.raiseHighWater(dir, []uint64{version}); err != nil {
+		return 0, err
+	}
+
+	return version, syncDir(dir)
+}
+
+func (s *FS) Get(ctx context.Context, key Key) (Entry, error) {
+	versions, err := s.versions(s.path(key))
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if len(versions) == 0 {
+		return Entry{}, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	return s.GetVersion(ctx, key, versions[len(versions)-1])
+}
+
+func (s *FS) GetVersion(_ context.Context, key Key, version uint64) (Entry, error) {
+	data, err := os.ReadFile(filepath.Join(s.path(key), versionName(version)))
+	if errors.Is(err, os.ErrNotExist) {
+		return Entry{}, fmt.Errorf("%w: %s version %d", ErrNotFound, key, version)
+	}
+
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return Entry{Version: version, Data: data}, nil
+}
+
+func (s *FS) Versions(_ context.Context, key Key) ([]uint64, error) {
+	return s.versions(s.path(key))
+}
+
+func (s *FS) Rollback(ctx context.Context, key Key) (Entry, error) {
+	dir := s.path(key)
+
+	versions, err := s.versions(dir)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if len(versions) == 0 {
+		return Entry{}, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	if len(versions) == 1 {
+		return Entry{}, fmt.Errorf("%w: %s", ErrNoPrevious, key)
+	}
+
+	if err := s.raiseHighWater(dir, versions); err != nil {
+		return Entry{}, err
+	}
+
+	if err := os.Remove(filepath.Join(dir, versionName(versions[len(versions)-1]))); err != nil {
+		return Entry{}, err
+	}
+
+	if err := syncDir(dir); err != nil {
+		return Entry{}, err
+	}
+
+	return s.GetVersion(ctx, key, versions[len(versions)-2])
+}
+
+func (s *FS) Delete(_ context.Context, key Key) error {
+	dir := s.path(key)
+
+	versions, err := s.versions(dir)
+	if err != nil {
+		return err
+	}
+
+	if err := s.raiseHighWater(dir, versions); err != nil {
+		return err
+	}
+
+	for _, version := range versions {
+		if err := os.Remove(filepath.Join(dir, versionName(version))); err != nil {
+			return err
+		}
+	}
+
+	return syncDir(dir)
+}
+
+func (s *FS) Lock(ctx context.Context, key Key) (Unlocker, error) {
+	dir := s.path(key)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, lockFile), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			return fileLock{f}, nil
+		}
+
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			f.Close()
+			return nil, err
+		}
+
+		select {
+		case <-time.After(lockInterval):
+		case <-ctx.Done():
+			f.Close()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+type fileLock struct {
+	f *os.File
+}
+
+func (l fileLock) Unlock() error {
+	// Closing the file releases the lock.
+	return l.f.Close()
+}
+
+// versions lists the versions stored in dir, oldest first.
+func (s *FS) versions(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []uint64
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), shareExt)
+		if !ok {
+			continue
+		}
+
+		version, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	return versions, nil
+}
+
+// highWater returns the highest version ever assigned in dir, given the
+// versions stored in dir, oldest first.
+func (s *FS) highWater(dir string, versions []uint64) (uint64, error) {
+	var version uint64
+
+	data, err := os.ReadFile(filepath.Join(dir, highWaterFile))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return 0, err
+	default:
+		if version, err = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64); err != nil {
+			return 0, fmt.Errorf("store: corrupted %s file in %s: %w", highWaterFile, dir, err)
+		}
+	}
+
+	if len(versions) > 0 {
+		version = max(version, versions[len(versions)-1])
+	}
+
+	return version, nil
+}
+
+// raiseHighWater records the latest of the versions, oldest first, as the
+// highest version ever assigned in dir unless a higher one is recorded.
+func (s *FS) raiseHighWater(dir string, versions []uint64) error {
+	recorded, err := s.highWater(dir, nil)
+	if err != nil {
+		return err
+	}
+
+	latest, err := s.highWater(dir, versions)
+	if err != nil || latest == recorded {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(strconv.FormatUint(latest, 10)); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, highWaterFile)); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+func versionName(version uint64) string {
+	return fmt.Sprintf("%020d%s", version, shareExt)
+}
+
+// syncDir makes renames and removals in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
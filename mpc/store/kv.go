@@ -0,0 +1,249 @@
+package store
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var keysharesBucket = []byte("keyshares")
+
+// KV stores keyshares in an embedded bbolt database, one nested bucket per key
+// with the big-endian version as the key of every entry. The sequence of the
+// bucket is the highest version ever assigned, so that versions removed by
+// Rollback or Delete are not reused.
+//
+// bbolt allows a single process to open the database at a time, so locks only
+// have to exclude the goroutines of that process.
+type KV struct {
+	db *bolt.DB
+
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+}
+
+// OpenKV opens or creates a bbolt database.
+//
+// Parameters:
+//   - path: string - the path of the database file.
+//   - timeout: time.Duration - how long to wait for another process to close the database; zero waits forever.
+//
+// Returns:
+//   - *KV: the store; the caller must Close it.
+//   - error: an error if the database can not be opened.
+func OpenKV(path string, timeout time.Duration) (*KV, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(keysharesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &KV{db: db, locks: make(map[string]chan struct{})}, nil
+}
+
+// Close closes the database.
+func (s *KV) Close() error {
+	return s.db.Close()
+}
+
+func versionKey(version uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, version)
+}
+
+func (s *KV) Put(_ context.Context, key Key, data []byte) (uint64, error) {
+	var version uint64
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(keysharesBucket).CreateBucketIfNotExists([]byte(key.String()))
+		if err != nil {
+			return err
+		}
+
+		version = b.Sequence()
+		if last, _ := b.Cursor().Last(); last != nil {
+			version = max(version, binary.BigEndian.Uint64(last))
+		}
+
+		version++
+
+		if err := b.SetSequence(version); err != nil {
+			return err
+		}
+
+		return b.Put(versionKey(version), data)
+	})
+
+	return version, err
+}
+
+func (s *KV) Get(_ context.Context, key Key) (Entry, error) {
+	var entry Entry
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(keysharesBucket).Bucket([]byte(key.String()))
+		if b == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+
+		k, v := b.Cursor().Last()
+		if k == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+
+		entry = Entry{Version: binary.BigEndian.Uint64(k), Data: append([]byte(nil), v...)}
+
+		return nil
+	})
+
+	return entry, err
+}
+
+func (s *KV) GetVersion(_ context.Context, key Key, version uint64) (Entry, error) {
+	var entry Entry
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var v []byte
+		if b := tx.Bucket(keysharesBucket).Bucket([]byte(key.String())); b != nil {
+			v = b.Get(versionKey(version))
+		}
+
+		if v == nil {
+			return fmt.Errorf("%w: %s version %d", ErrNotFound, key, version)
+		}
+
+		entry = Entry{Version: version, Data: append([]byte(nil), v...)}
+
+		return nil
+	})
+
+	return entry, err
+}
+
+func (s *KV) Versions(_ context.Context, key Key) ([]uint64, error) {
+	var versions []uint64
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(keysharesBucket).Bucket([]byte(key.String()))
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k, _ []byte) error {
+			versions = append(versions, binary.BigEndian.Uint64(k))
+			return nil
+		})
+	})
+
+	return versions, err
+}
+
+func (s *KV) Rollback(_ context.Context, key Key) (Entry, error) {
+	var entry Entry
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(keysharesBucket).Bucket([]byte(key.String()))
+		if b == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+
+		c := b.Cursor()
+
+		last, _ := c.Last()
+		if last == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+
+		k, v := c.Prev()
+		if k == nil {
+			return fmt.Errorf("%w: %s", ErrNoPrevious, key)
+		}
+
+		entry = Entry{Version: binary.BigEndian.Uint64(k), Data: append([]byte(nil), v...)}
+
+		return b.Delete(last)
+	})
+
+	return entry, err
+}
+
+func (s *KV) Delete(_ context.Context, key Key) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		// The bucket stays, so that its sequence keeps the deleted versions from being reused.
+		b := tx.Bucket(keysharesBucket).Bucket([]byte(key.String()))
+		if b == nil {
+			return nil
+		}
+
+		var versions [][]byte
+
+		err := b.ForEach(func(k, _ []byte) error {
+			versions = append(versions, k)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range versions {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *KV) Lock(ctx context.Context, key Key) (Unlocker, error) {
+	name := key.String()
+
+	for {
+		s.mu.Lock()
+		held, ok := s.locks[name]
+		if !ok {
+			released := make(chan struct{})
+			s.locks[name] = released
+			s.mu.Unlock()
+
+			return &kvLock{store: s, name: name, released: released}, nil
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-held:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+type kvLock struct {
+	store    *KV
+	name     string
+	released chan struct{}
+	once     sync.Once
+}
+
+func (l *kvLock) Unlock() error {
+	l.once.Do(func() {
+		l.store.mu.Lock()
+		delete(l.store.locks, l.name)
+		l.store.mu.Unlock()
+
+		close(l.released)
+	})
+
+	return nil
+}
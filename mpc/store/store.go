@@ -0,0 +1,122 @@
+// Package store keeps serialized keyshares, keyed by key ID and local party ID.
+//
+// Every write creates a new version, so that a failed refresh or QC can roll
+// back to the previous keyshare. Exclusive locks keep two signers, in the same
+// or in different processes, from using the same keyshare at once.
+//
+// Key functionalities include:
+// - The KeyshareStore interface
+// - A filesystem backend with atomic writes, 0600 files and flock-based locks
+// - An embedded bbolt key-value backend
+// - Saving and loading mpc.Keyshare values
+package store
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/vultisig/go-wrappers/mpc"
+)
+
+var (
+	// ErrNotFound is returned when a key or a version does not exist.
+	ErrNotFound = errors.New("store: not found")
+	// ErrNoPrevious is returned when rolling back the only version of a keyshare.
+	ErrNoPrevious = errors.New("store: no previous version")
+)
+
+// Key identifies the keyshares of one party of one key.
+type Key struct {
+	// KeyID is the key ID of the keyshare.
+	KeyID []byte
+	// PartyID is the ID of the local party.
+	PartyID string
+}
+
+func (k Key) String() string {
+	return hex.EncodeToString(k.KeyID) + "/" + k.PartyID
+}
+
+// Entry is one version of a keyshare.
+type Entry struct {
+	// Version starts at 1 and grows with every Put.
+	Version uint64
+	// Data is the serialized keyshare, possibly sealed by the vault package.
+	Data []byte
+}
+
+// Unlocker releases a lock.
+type Unlocker interface {
+	Unlock() error
+}
+
+// KeyshareStore stores versioned keyshares. Implementations are safe for concurrent use.
+type KeyshareStore interface {
+	// Put stores a new version of a keyshare and returns its version. Versions
+	// only grow: a version removed by Rollback or Delete is never reused.
+	Put(ctx context.Context, key Key, data []byte) (uint64, error)
+	// Get returns the latest version of a keyshare.
+	Get(ctx context.Context, key Key) (Entry, error)
+	// GetVersion returns a given version of a keyshare.
+	GetVersion(ctx context.Context, key Key, version uint64) (Entry, error)
+	// Versions lists the stored versions of a keyshare, oldest first.
+	Versions(ctx context.Context, key Key) ([]uint64, error)
+	// Rollback deletes the latest version of a keyshare and returns the previous one.
+	Rollback(ctx context.Context, key Key) (Entry, error)
+	// Delete deletes all versions of a keyshare.
+	Delete(ctx context.Context, key Key) error
+	// Lock blocks until it holds the exclusive lock of a keyshare or the context is done.
+	Lock(ctx context.Context, key Key) (Unlocker, error)
+}
+
+// Save stores a new version of a keyshare under its key ID.
+//
+// Parameters:
+//   - ctx: context.Context - cancels the operation.
+//   - s: KeyshareStore - the store.
+//   - partyID: string - the ID of the local party.
+//   - share: mpc.Keyshare - the keyshare.
+//
+// Returns:
+//   - uint64: the version of the stored keyshare.
+//   - error: an error if the keyshare can not be serialized or stored.
+func Save(ctx context.Context, s KeyshareStore, partyID string, share mpc.Keyshare) (uint64, error) {
+	keyID, err := share.KeyID()
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := share.ToBytes()
+	if err != nil {
+		return 0, err
+	}
+
+	return s.Put(ctx, Key{KeyID: keyID, PartyID: partyID}, data)
+}
+
+// Load restores the latest version of a keyshare.
+//
+// Parameters:
+//   - ctx: context.Context - cancels the operation.
+//   - s: KeyshareStore - the store.
+//   - scheme: mpc.Scheme - the scheme of the keyshare.
+//   - key: Key - the key of the keyshare.
+//
+// Returns:
+//   - mpc.Keyshare: the keyshare; the caller must Close it.
+//   - error: ErrNotFound if there is no such keyshare.
+func Load(ctx context.Context, s KeyshareStore, scheme mpc.Scheme, key Key) (mpc.Keyshare, error) {
+	entry, err := s.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	share, err := scheme.KeyshareFromBytes(entry.Data)
+	if err != nil {
+		return nil, fmt.Errorf("store: %s version %d: %w", key, entry.Version, err)
+	}
+
+	return share, nil
+}
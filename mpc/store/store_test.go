@@ -0,0 +1,197 @@
+package store_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vultisig/go-wrappers/mpc"
+	"github.com/vultisig/go-wrappers/mpc/router"
+	"github.com/vultisig/go-wrappers/mpc/store"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func backends(t *testing.T) map[string]store.KeyshareStore {
+	fs, err := store.NewFS(filepath.Join(t.TempDir(), "fs"))
+	assert.NoError(t, err)
+
+	kv, err := store.OpenKV(filepath.Join(t.TempDir(), "keyshares.db"), time.Second)
+	assert.NoError(t, err)
+	t.Cleanup(func() { kv.Close() })
+
+	return map[string]store.KeyshareStore{"fs": fs, "kv": kv}
+}
+
+func TestVersions(t *testing.T) {
+	t.Parallel()
+
+	for name, s := range backends(t) {
+		s := s
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			key := store.Key{KeyID: []byte{1, 2, 3}, PartyID: "p1"}
+
+			_, err := s.Get(ctx, key)
+			assert.ErrorIs(t, err, store.ErrNotFound)
+
+			for i, data := range []string{"v1", "v2", "v3"} {
+				version, err := s.Put(ctx, key, []byte(data))
+				assert.NoError(t, err)
+				assert.Equal(t, uint64(i+1), version)
+			}
+
+			versions, err := s.Versions(ctx, key)
+			assert.NoError(t, err)
+			assert.Equal(t, []uint64{1, 2, 3}, versions)
+
+			entry, err := s.Get(ctx, key)
+			assert.NoError(t, err)
+			assert.Equal(t, store.Entry{Version: 3, Data: []byte("v3")}, entry)
+
+			entry, err = s.GetVersion(ctx, key, 1)
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("v1"), entry.Data)
+
+			// A failed refresh rolls back to the previous keyshare.
+			entry, err = s.Rollback(ctx, key)
+			assert.NoError(t, err)
+			assert.Equal(t, store.Entry{Version: 2, Data: []byte("v2")}, entry)
+
+			entry, err = s.Get(ctx, key)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(2), entry.Version)
+
+			// A rolled back version is never reused.
+			version, err := s.Put(ctx, key, []byte("v4"))
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(4), version)
+
+			versions, err = s.Versions(ctx, key)
+			assert.NoError(t, err)
+			assert.Equal(t, []uint64{1, 2, 4}, versions)
+
+			_, err = s.Rollback(ctx, key)
+			assert.NoError(t, err)
+
+			_, err = s.Rollback(ctx, key)
+			assert.NoError(t, err)
+
+			_, err = s.Rollback(ctx, key)
+			assert.ErrorIs(t, err, store.ErrNoPrevious)
+
+			// Other parties of the same key are separate.
+			_, err = s.Get(ctx, store.Key{KeyID: key.KeyID, PartyID: "p2"})
+			assert.ErrorIs(t, err, store.ErrNotFound)
+
+			assert.NoError(t, s.Delete(ctx, key))
+
+			_, err = s.Get(ctx, key)
+			assert.ErrorIs(t, err, store.ErrNotFound)
+
+			// Neither are deleted versions.
+			version, err = s.Put(ctx, key, []byte("v5"))
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(5), version)
+		})
+	}
+}
+
+func TestLock(t *testing.T) {
+	t.Parallel()
+
+	for name, s := range backends(t) {
+		s := s
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			key := store.Key{KeyID: []byte{4, 5, 6}, PartyID: "p1"}
+
+			lock, err := s.Lock(context.Background(), key)
+			assert.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			_, err = s.Lock(ctx, key)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+			// Other keys are not affected.
+			other, err := s.Lock(context.Background(), store.Key{KeyID: key.KeyID, PartyID: "p2"})
+			assert.NoError(t, err)
+			assert.NoError(t, other.Unlock())
+
+			acquired := make(chan store.Unlocker)
+			go func() {
+				l, err := s.Lock(context.Background(), key)
+				assert.NoError(t, err)
+				acquired <- l
+			}()
+
+			assert.NoError(t, lock.Unlock())
+			assert.NoError(t, (<-acquired).Unlock())
+		})
+	}
+}
+
+func TestFilePermissions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	s, err := store.NewFS(dir)
+	assert.NoError(t, err)
+
+	_, err = s.Put(context.Background(), store.Key{KeyID: []byte{7}, PartyID: "p1"}, []byte("secret"))
+	assert.NoError(t, err)
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), path)
+		}
+
+		return err
+	})
+	assert.NoError(t, err)
+}
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	ids := []string{"p1", "p2"}
+
+	setup, err := mpc.EdDSA.KeygenSetupMsgNew(2, nil, ids)
+	assert.NoError(t, err)
+
+	sessions := make(map[string]mpc.Finisher[mpc.Keyshare], len(ids))
+	for _, id := range ids {
+		sessions[id], err = mpc.EdDSA.KeygenSessionFromSetup(setup, id)
+		assert.NoError(t, err)
+	}
+
+	shares, err := router.Run(context.Background(), sessions, time.Minute)
+	assert.NoError(t, err)
+
+	keyID, err := shares["p1"].KeyID()
+	assert.NoError(t, err)
+
+	publicKey, err := shares["p1"].PublicKey()
+	assert.NoError(t, err)
+
+	for name, s := range backends(t) {
+		version, err := store.Save(context.Background(), s, "p1", shares["p1"])
+		assert.NoError(t, err, name)
+		assert.Equal(t, uint64(1), version, name)
+
+		share, err := store.Load(context.Background(), s, mpc.EdDSA, store.Key{KeyID: keyID, PartyID: "p1"})
+		assert.NoError(t, err, name)
+
+		pk, err := share.PublicKey()
+		assert.NoError(t, err, name)
+		assert.Equal(t, publicKey, pk, name)
+	}
+}
@@ -0,0 +1,319 @@
+// Package vault seals serialized keyshares of either scheme with a password.
+//
+// The secret keyshare is encrypted with AES-256-GCM under a key derived from
+// the password with Argon2id or scrypt; the KDF parameters are stored with the
+// vault so that they can be raised over time without breaking old vaults. The
+// scheme, the public key, the key ID and the chain code are stored in the clear
+// and authenticated as associated data, so that a vault can be listed without
+// the password but not tampered with.
+//
+// Key functionalities include:
+// - Sealing and opening mpc.Keyshare values with a password
+// - Versioned Argon2id and scrypt parameters
+// - Public metadata readable without the password
+// - Password rotation without re-running MPC
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/vultisig/go-wrappers/mpc"
+)
+
+// Version is the current version of the vault format.
+const Version = 1
+
+const (
+	// Argon2id selects the Argon2id key derivation function.
+	Argon2id = "argon2id"
+	// Scrypt selects the scrypt key derivation function.
+	Scrypt = "scrypt"
+
+	keySize  = 32
+	saltSize = 16
+
+	// The bounds below keep a crafted vault from making Open run for hours or
+	// exhaust memory before the password is even checked.
+
+	// maxMemory bounds the Argon2id memory in KiB a vault may ask for (4 GiB).
+	maxMemory = 4 << 20
+	// maxTime bounds the number of Argon2id passes a vault may ask for.
+	maxTime = 16
+	// maxThreads bounds the Argon2id parallelism a vault may ask for.
+	maxThreads = 64
+	// maxScryptN bounds the scrypt cost a vault may ask for.
+	maxScryptN = 1 << 22
+	// maxScryptR bounds the scrypt block size a vault may ask for.
+	maxScryptR = 32
+	// maxScryptP bounds the scrypt parallelism a vault may ask for.
+	maxScryptP = 16
+	// maxScryptMemory bounds the scrypt memory, 128·N·r bytes, a vault may ask for (4 GiB).
+	maxScryptMemory = 4 << 30
+)
+
+var (
+	// ErrPassword is returned when a vault can not be opened, either because the
+	// password is wrong or because the vault has been tampered with.
+	ErrPassword = errors.New("vault: wrong password or corrupted vault")
+	// ErrUnsupported is returned for vaults of an unknown version or KDF.
+	ErrUnsupported = errors.New("vault: unsupported vault")
+)
+
+// KDFParams are the parameters of the password-based key derivation.
+type KDFParams struct {
+	// Algorithm is Argon2id or Scrypt.
+	Algorithm string `json:"algorithm"`
+	// Salt is generated when a vault is sealed.
+	Salt []byte `json:"salt"`
+
+	// Time is the number of Argon2id passes.
+	Time uint32 `json:"time,omitempty"`
+	// Memory is the Argon2id memory in KiB.
+	Memory uint32 `json:"memory,omitempty"`
+	// Threads is the Argon2id parallelism.
+	Threads uint8 `json:"threads,omitempty"`
+
+	// N is the scrypt CPU/memory cost, a power of two.
+	N int `json:"n,omitempty"`
+	// R is the scrypt block size.
+	R int `json:"r,omitempty"`
+	// P is the scrypt parallelism.
+	P int `json:"p,omitempty"`
+}
+
+// DefaultArgon2id returns the recommended Argon2id parameters.
+func DefaultArgon2id() KDFParams {
+	return KDFParams{Algorithm: Argon2id, Time: 3, Memory: 64 * 1024, Threads: 4}
+}
+
+// DefaultScrypt returns the recommended scrypt parameters.
+func DefaultScrypt() KDFParams {
+	return KDFParams{Algorithm: Scrypt, N: 1 << 17, R: 8, P: 1}
+}
+
+func (p KDFParams) deriveKey(password []byte) ([]byte, error) {
+	switch p.Algorithm {
+	case Argon2id:
+		if p.Time == 0 || p.Memory == 0 || p.Threads == 0 || p.Time > maxTime || p.Memory > maxMemory || p.Threads > maxThreads {
+			return nil, fmt.Errorf("%w: argon2id parameters out of range", ErrUnsupported)
+		}
+
+		return argon2.IDKey(password, p.Salt, p.Time, p.Memory, p.Threads, keySize), nil
+	case Scrypt:
+		if p.N <= 1 || p.R <= 0 || p.P <= 0 || p.N > maxScryptN || p.R > maxScryptR || p.P > maxScryptP ||
+			128*uint64(p.N)*uint64(p.R) > maxScryptMemory {
+			return nil, fmt.Errorf("%w: scrypt parameters out of range", ErrUnsupported)
+		}
+
+		return scrypt.Key(password, p.Salt, p.N, p.R, p.P, keySize)
+	default:
+		return nil, fmt.Errorf("%w: kdf %q", ErrUnsupported, p.Algorithm)
+	}
+}
+
+// Metadata is the public part of a vault.
+type Metadata struct {
+	Scheme    mpc.Kind `json:"scheme"`
+	PublicKey []byte   `json:"public_key"`
+	KeyID     []byte   `json:"key_id"`
+	ChainCode []byte   `json:"chain_code"`
+}
+
+// Vault is a password-sealed keyshare.
+type Vault struct {
+	Version    int       `json:"version"`
+	Metadata   Metadata  `json:"metadata"`
+	KDF        KDFParams `json:"kdf"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
+// Seal encrypts a keyshare with a password.
+//
+// Parameters:
+//   - share: mpc.Keyshare - the keyshare to seal.
+//   - password: []byte - the password.
+//   - kdf: KDFParams - the key derivation parameters; a fresh salt is generated.
+//
+// Returns:
+//   - *Vault: the sealed keyshare.
+//   - error: an error if the keyshare can not be serialized or encrypted.
+func Seal(share mpc.Keyshare, password []byte, kdf KDFParams) (*Vault, error) {
+	publicKey, err := share.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	keyID, err := share.KeyID()
+	if err != nil {
+		return nil, err
+	}
+
+	chainCode, err := share.ChainCode()
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := share.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := Metadata{Scheme: share.Scheme(), PublicKey: publicKey, KeyID: keyID, ChainCode: chainCode}
+
+	return seal(metadata, secret, password, kdf)
+}
+
+func seal(metadata Metadata, secret []byte, password []byte, kdf KDFParams) (*Vault, error) {
+	kdf.Salt = make([]byte, saltSize)
+	if _, err := rand.Read(kdf.Salt); err != nil {
+		return nil, err
+	}
+
+	v := &Vault{Version: Version, Metadata: metadata, KDF: kdf}
+
+	aead, err := v.aead(password)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(v.Nonce); err != nil {
+		return nil, err
+	}
+
+	ad, err := v.additionalData()
+	if err != nil {
+		return nil, err
+	}
+
+	v.Ciphertext = aead.Seal(nil, v.Nonce, secret, ad)
+
+	return v, nil
+}
+
+func (v *Vault) aead(password []byte) (cipher.AEAD, error) {
+	key, err := v.KDF.deriveKey(password)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// additionalData authenticates the version, the public metadata and the KDF parameters.
+func (v *Vault) additionalData() ([]byte, error) {
+	return json.Marshal(struct {
+		Version  int       `json:"version"`
+		Metadata Metadata  `json:"metadata"`
+		KDF      KDFParams `json:"kdf"`
+	}{v.Version, v.Metadata, v.KDF})
+}
+
+// secret decrypts the serialized keyshare.
+func (v *Vault) secret(password []byte) ([]byte, error) {
+	if v.Version != Version {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupported, v.Version)
+	}
+
+	aead, err := v.aead(password)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(v.Nonce) != aead.NonceSize() {
+		return nil, ErrPassword
+	}
+
+	ad, err := v.additionalData()
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := aead.Open(nil, v.Nonce, v.Ciphertext, ad)
+	if err != nil {
+		return nil, ErrPassword
+	}
+
+	return secret, nil
+}
+
+// Open decrypts the vault and restores the keyshare.
+//
+// Parameters:
+//   - password: []byte - the password.
+//
+// Returns:
+//   - mpc.Keyshare: the keyshare; the caller must Close it.
+//   - error: ErrPassword if the password is wrong or the vault has been tampered with.
+func (v *Vault) Open(password []byte) (mpc.Keyshare, error) {
+	secret, err := v.secret(password)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme, err := mpc.ByKind(v.Metadata.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return scheme.KeyshareFromBytes(secret)
+}
+
+// Rotate re-seals the vault under a new password. The keyshare is not touched.
+//
+// Parameters:
+//   - oldPassword: []byte - the current password.
+//   - newPassword: []byte - the new password.
+//   - kdf: KDFParams - the key derivation parameters for the new password.
+//
+// Returns:
+//   - *Vault: the re-sealed vault.
+//   - error: ErrPassword if the current password is wrong.
+func (v *Vault) Rotate(oldPassword []byte, newPassword []byte, kdf KDFParams) (*Vault, error) {
+	secret, err := v.secret(oldPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	return seal(v.Metadata, secret, newPassword, kdf)
+}
+
+// Marshal encodes the vault as JSON.
+func (v *Vault) Marshal() ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes a vault from JSON. The password is not needed.
+//
+// Parameters:
+//   - data: []byte - the encoded vault.
+//
+// Returns:
+//   - *Vault: the vault, whose Metadata can be read right away.
+//   - error: an error if the data is not a vault of a supported version.
+func Unmarshal(data []byte) (*Vault, error) {
+	var v Vault
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	if v.Version != Version {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupported, v.Version)
+	}
+
+	return &v, nil
+}
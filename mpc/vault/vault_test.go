@@ -0,0 +1,188 @@
+package vault_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vultisig/go-wrappers/mpc"
+	"github.com/vultisig/go-wrappers/mpc/router"
+	"github.com/vultisig/go-wrappers/mpc/vault"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Cheap parameters keep the tests fast.
+var (
+	fastArgon2id = vault.KDFParams{Algorithm: vault.Argon2id, Time: 1, Memory: 1024, Threads: 1}
+	fastScrypt   = vault.KDFParams{Algorithm: vault.Scrypt, N: 1 << 10, R: 8, P: 1}
+)
+
+func keygen(t *testing.T, scheme mpc.Scheme) mpc.Keyshare {
+	ids := []string{"p1", "p2"}
+
+	setup, err := scheme.KeygenSetupMsgNew(2, nil, ids)
+	assert.NoError(t, err)
+
+	sessions := make(map[string]mpc.Finisher[mpc.Keyshare], len(ids))
+	for _, id := range ids {
+		sessions[id], err = scheme.KeygenSessionFromSetup(setup, id)
+		assert.NoError(t, err)
+	}
+
+	shares, err := router.Run(context.Background(), sessions, time.Minute)
+	assert.NoError(t, err)
+
+	return shares["p1"]
+}
+
+func TestVault(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		scheme mpc.Scheme
+		kdf    vault.KDFParams
+	}{
+		{name: "ecdsa argon2id", scheme: mpc.ECDSA, kdf: fastArgon2id},
+		{name: "ecdsa scrypt", scheme: mpc.ECDSA, kdf: fastScrypt},
+		{name: "eddsa argon2id", scheme: mpc.EdDSA, kdf: fastArgon2id},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			share := keygen(t, tc.scheme)
+
+			publicKey, err := share.PublicKey()
+			assert.NoError(t, err)
+
+			v, err := vault.Seal(share, []byte("correct horse"), tc.kdf)
+			assert.NoError(t, err)
+
+			data, err := v.Marshal()
+			assert.NoError(t, err)
+
+			v, err = vault.Unmarshal(data)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.scheme.Kind(), v.Metadata.Scheme)
+			assert.Equal(t, publicKey, v.Metadata.PublicKey)
+
+			_, err = v.Open([]byte("wrong"))
+			assert.ErrorIs(t, err, vault.ErrPassword)
+
+			opened, err := v.Open([]byte("correct horse"))
+			assert.NoError(t, err)
+
+			pk, err := opened.PublicKey()
+			assert.NoError(t, err)
+			assert.Equal(t, publicKey, pk)
+
+			// Rotation keeps the keyshare and drops the old password.
+			rotated, err := v.Rotate([]byte("correct horse"), []byte("battery staple"), vault.DefaultArgon2id())
+			assert.NoError(t, err)
+			assert.NotEqual(t, v.KDF.Salt, rotated.KDF.Salt)
+
+			_, err = rotated.Open([]byte("correct horse"))
+			assert.ErrorIs(t, err, vault.ErrPassword)
+
+			opened, err = rotated.Open([]byte("battery staple"))
+			assert.NoError(t, err)
+
+			pk, err = opened.PublicKey()
+			assert.NoError(t, err)
+			assert.Equal(t, publicKey, pk)
+		})
+	}
+}
+
+func TestTamper(t *testing.T) {
+	t.Parallel()
+
+	share := keygen(t, mpc.EdDSA)
+
+	testCases := []struct {
+		name   string
+		tamper func(v *vault.Vault)
+		err    error
+	}{
+		{
+			name:   "public key",
+			tamper: func(v *vault.Vault) { v.Metadata.PublicKey[0] ^= 1 },
+			err:    vault.ErrPassword,
+		},
+		{
+			name:   "scheme",
+			tamper: func(v *vault.Vault) { v.Metadata.Scheme = mpc.KindECDSA },
+			err:    vault.ErrPassword,
+		},
+		{
+			name:   "ciphertext",
+			tamper: func(v *vault.Vault) { v.Ciphertext[0] ^= 1 },
+			err:    vault.ErrPassword,
+		},
+		{
+			name:   "kdf",
+			tamper: func(v *vault.Vault) { v.KDF.Algorithm = "pbkdf2" },
+			err:    vault.ErrUnsupported,
+		},
+		{
+			name:   "version",
+			tamper: func(v *vault.Vault) { v.Version = 2 },
+			err:    vault.ErrUnsupported,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			v, err := vault.Seal(share, []byte("password"), fastArgon2id)
+			assert.NoError(t, err)
+
+			tc.tamper(v)
+
+			_, err = v.Open([]byte("password"))
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestKDFBounds(t *testing.T) {
+	t.Parallel()
+
+	share := keygen(t, mpc.EdDSA)
+
+	testCases := []struct {
+		name string
+		kdf  func(p *vault.KDFParams)
+	}{
+		{name: "argon2id time", kdf: func(p *vault.KDFParams) { *p = fastArgon2id; p.Time = 17 }},
+		{name: "argon2id memory", kdf: func(p *vault.KDFParams) { *p = fastArgon2id; p.Memory = 4<<20 + 1 }},
+		{name: "argon2id threads", kdf: func(p *vault.KDFParams) { *p = fastArgon2id; p.Threads = 65 }},
+		{name: "argon2id zero time", kdf: func(p *vault.KDFParams) { *p = fastArgon2id; p.Time = 0 }},
+		{name: "scrypt n", kdf: func(p *vault.KDFParams) { *p = fastScrypt; p.N = 1 << 23 }},
+		{name: "scrypt r", kdf: func(p *vault.KDFParams) { *p = fastScrypt; p.R = 33 }},
+		{name: "scrypt p", kdf: func(p *vault.KDFParams) { *p = fastScrypt; p.P = 17 }},
+		{name: "scrypt memory", kdf: func(p *vault.KDFParams) { *p = fastScrypt; p.N, p.R = 1<<22, 9 }},
+		{name: "scrypt zero r", kdf: func(p *vault.KDFParams) { *p = fastScrypt; p.R = 0 }},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			v, err := vault.Seal(share, []byte("password"), fastArgon2id)
+			assert.NoError(t, err)
+
+			tc.kdf(&v.KDF)
+
+			_, err = v.Open([]byte("password"))
+			assert.ErrorIs(t, err, vault.ErrUnsupported)
+		})
+	}
+}